@@ -0,0 +1,76 @@
+package otelpgx
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// BatchIndexKey represents the position of a query within its batch,
+// starting at 0.
+const BatchIndexKey = attribute.Key("db.batch.index")
+
+// batchQueryEvent is the name of the span events recording batch queries,
+// see WithBatchQueryEvents.
+const batchQueryEvent = "batch query"
+
+type batchStateKey struct{}
+
+// batchState is stored in the context by TraceBatchStart to time the queries
+// of the batch.
+type batchState struct {
+	index int
+	last  time.Time
+}
+
+// nextBatchQuery returns the index of the batch query being traced in ctx,
+// and the time between the previous TraceBatchQuery call, or the start of
+// the batch, and now. It returns false if ctx carries no batch state.
+func nextBatchQuery(ctx context.Context) (index int, start, end time.Time, ok bool) {
+	state, ok := ctx.Value(batchStateKey{}).(*batchState)
+	if !ok {
+		return 0, time.Time{}, time.Time{}, false
+	}
+
+	index, start, end = state.index, state.last, time.Now()
+	state.index++
+	state.last = end
+
+	return index, start, end, true
+}
+
+// addBatchQueryEvent records a batch query as an event on the batch span.
+func (t *Tracer) addBatchQueryEvent(ctx context.Context, index int, start, end time.Time, data pgx.TraceBatchQueryData) {
+	attrs := []attribute.KeyValue{
+		BatchIndexKey.Int(index),
+		DurationKey.Float64(milliseconds(end.Sub(start))),
+	}
+
+	if t.logSQLStatement {
+		attrs = append(attrs, t.semConvStability.statementAttributes(t.statement(data.SQL))...)
+		if t.includeParams {
+			attrs = append(attrs, t.paramsAttribute(data.Args))
+		}
+	}
+
+	span := trace.SpanFromContext(ctx)
+
+	attrs = append(attrs, t.pgErrorAttributes(data.Err)...)
+	attrs = append(attrs, errorCauseAttributes(ctx, data.Err)...)
+
+	switch {
+	case t.classifyError(data.Err) == codes.Error:
+		attrs = append(attrs, ErrorKey.Bool(true), semconv.ExceptionMessage(data.Err.Error()))
+		attrs = append(attrs, t.errorAttributes(data.Err)...)
+		span.SetStatus(codes.Error, data.Err.Error())
+	case data.Err == nil:
+		attrs = append(attrs, RowsAffectedKey.Int64(data.CommandTag.RowsAffected()))
+	}
+
+	span.AddEvent(batchQueryEvent, trace.WithTimestamp(end), trace.WithAttributes(attrs...))
+}
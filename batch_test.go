@@ -0,0 +1,85 @@
+package otelpgx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracer_TraceBatchQuery(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []Option
+		wantSpans int
+	}{
+		{
+			name:      "Spans",
+			wantSpans: 3,
+		},
+		{
+			name:      "Events",
+			opts:      []Option{WithBatchQueryEvents()},
+			wantSpans: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			tr := NewTracer(append([]Option{WithTracerProvider(tp), WithTrimSQLInSpanName()}, tt.opts...)...)
+
+			ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+			defer parent.End()
+
+			ctx = tr.TraceBatchStart(ctx, nil, pgx.TraceBatchStartData{})
+			for _, sql := range []string{"SELECT 1", "SELECT 2"} {
+				time.Sleep(time.Millisecond)
+				tr.TraceBatchQuery(ctx, nil, pgx.TraceBatchQueryData{SQL: sql})
+			}
+			tr.TraceBatchEnd(ctx, nil, pgx.TraceBatchEndData{})
+
+			spans := recorder.Ended()
+			if len(spans) != tt.wantSpans {
+				t.Fatalf("got %d ended spans, want %d", len(spans), tt.wantSpans)
+			}
+
+			batch := spans[len(spans)-1]
+			if batch.Name() != "batch start" {
+				t.Fatalf("unexpected batch span %q", batch.Name())
+			}
+
+			if tt.wantSpans == 1 {
+				events := batch.Events()
+				if len(events) != 2 {
+					t.Fatalf("got %d events, want 2", len(events))
+				}
+				for i, e := range events {
+					attrs := attribute.NewSet(e.Attributes...)
+					if v, _ := attrs.Value(BatchIndexKey); e.Name != batchQueryEvent || v.AsInt64() != int64(i) {
+						t.Errorf("unexpected event %v", e)
+					}
+				}
+				return
+			}
+
+			for i, span := range spans[:2] {
+				if span.Name() != "batch query SELECT" {
+					t.Errorf("span name = %q, want %q", span.Name(), "batch query SELECT")
+				}
+				if d := span.EndTime().Sub(span.StartTime()); d < time.Millisecond {
+					t.Errorf("span duration = %v, want at least 1ms", d)
+				}
+				attrs := attribute.NewSet(span.Attributes()...)
+				if v, _ := attrs.Value(BatchIndexKey); v.AsInt64() != int64(i) {
+					t.Errorf("%v = %v, want %d", BatchIndexKey, v.AsInt64(), i)
+				}
+			}
+		})
+	}
+}
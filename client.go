@@ -33,13 +33,21 @@ type ResourceConfig struct {
 }
 
 // parsePgxConfig parses the pgxpool.Config and returns attributes for the resource.
-func parsePgxConfig(config *pgxpool.Config) []attribute.KeyValue {
+func parsePgxConfig(config *pgxpool.Config, stability SemConvStability) []attribute.KeyValue {
 	cc := config.ConnConfig
 
-	attrs := []attribute.KeyValue{
-		semconv.DBSystemPostgreSQL,
-		semconv.DBName(cc.Database),
-		semconv.DBConnectionString(cc.ConnString()),
+	attrs := stability.systemAttributes()
+	if stability.emitOld() {
+		attrs = append(attrs,
+			semconv.DBName(cc.Database),
+			semconv.DBConnectionString(cc.ConnString()),
+		)
+	}
+	if stability.emitNew() {
+		attrs = append(attrs, DBNamespaceKey.String(cc.Database))
+	}
+
+	attrs = append(attrs,
 		attribute.String(DBMaxConnLifetimeKey, config.MaxConnLifetime.String()),
 		attribute.String(DBMaxConnIdleTimeKey, config.MaxConnIdleTime.String()),
 		attribute.Int64(DBMaxConnsKey, int64(config.MaxConns)),
@@ -53,7 +61,7 @@ func parsePgxConfig(config *pgxpool.Config) []attribute.KeyValue {
 		attribute.String(DBConnectTimeoutKey, cc.ConnectTimeout.String()),
 		attribute.String(DBKerberosSrvNameKey, cc.KerberosSrvName),
 		attribute.String(DBKerberosSpnKey, cc.KerberosSpn),
-	}
+	)
 
 	for k, v := range cc.RuntimeParams {
 		keyValue := fmt.Sprintf("db.runtime_param.%s", strings.ToLower(k))
@@ -70,9 +78,9 @@ func createOTelResource(config *ResourceConfig, pc *pgxpool.Config, attrs ...att
 		semconv.DeploymentEnvironment(config.ServiceEnv),
 	}
 
-	finalAttrs := append(initialAttrs, parsePgxConfig(pc)...)
+	finalAttrs := append(initialAttrs, parsePgxConfig(pc, semConvStabilityFromEnv())...)
 
-	finalAttrs = append(initialAttrs, attrs...)
+	finalAttrs = append(finalAttrs, attrs...)
 
 	r, err := resource.Merge(
 		resource.Default(),
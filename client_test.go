@@ -0,0 +1,75 @@
+package otelpgx
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+)
+
+func TestCreateOTelResource(t *testing.T) {
+	pc, err := pgxpool.ParseConfig("postgres://app@db.example.com:6432/orders?pool_max_conns=7")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		env       string
+		wantKeys  []attribute.Key
+		wantNoKey []attribute.Key
+	}{
+		{
+			name:      "Old",
+			wantKeys:  []attribute.Key{semconv.DBSystemKey, semconv.DBNameKey},
+			wantNoKey: []attribute.Key{DBSystemNameKey, DBNamespaceKey},
+		},
+		{
+			name:      "New",
+			env:       "database",
+			wantKeys:  []attribute.Key{DBSystemNameKey, DBNamespaceKey},
+			wantNoKey: []attribute.Key{semconv.DBSystemKey, semconv.DBNameKey},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(semConvStabilityOptInEnv, tt.env)
+
+			r, err := createOTelResource(
+				&ResourceConfig{ServiceName: "orders-api", ServiceVersion: "1.2.3", ServiceEnv: "test"},
+				pc,
+				attribute.String("team", "payments"),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			attrs := r.Set()
+			want := map[attribute.Key]string{
+				semconv.ServiceNameKey: "orders-api",
+				"team":                 "payments",
+				DBHostKey:              "db.example.com",
+			}
+			for key, v := range want {
+				if got, _ := attrs.Value(key); got.AsString() != v {
+					t.Errorf("%v = %q, want %q", key, got.AsString(), v)
+				}
+			}
+			if got, _ := attrs.Value(DBMaxConnsKey); got.AsInt64() != 7 {
+				t.Errorf("%v = %v, want 7", DBMaxConnsKey, got.AsInt64())
+			}
+			for _, key := range tt.wantKeys {
+				if !attrs.HasValue(key) {
+					t.Errorf("missing attribute %v", key)
+				}
+			}
+			for _, key := range tt.wantNoKey {
+				if attrs.HasValue(key) {
+					t.Errorf("unexpected attribute %v", key)
+				}
+			}
+		})
+	}
+}
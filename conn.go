@@ -0,0 +1,146 @@
+package otelpgx
+
+import (
+	"crypto/tls"
+	"net"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// BackendPIDKey represents the PID of the server process serving the
+	// connection, as found in pg_stat_activity.pid.
+	BackendPIDKey = attribute.Key("pgx.backend_pid")
+	// ServerVersionKey represents the server_version reported by the server.
+	ServerVersionKey = attribute.Key("pgx.server_version")
+	// ApplicationNameKey represents the application_name of the connection.
+	ApplicationNameKey = attribute.Key("pgx.application_name")
+	// TLSKey represents whether the connection uses TLS.
+	TLSKey = attribute.Key("pgx.tls")
+)
+
+// ConnAttribute is a connection-level span attribute, see
+// WithConnectionAttributes.
+type ConnAttribute int
+
+const (
+	// ConnAttributeServer adds the host and port connected to, as
+	// net.peer.name and net.peer.port, or server.address and server.port.
+	ConnAttributeServer ConnAttribute = iota
+	// ConnAttributeUser adds the connection user as db.user. The stable
+	// semantic conventions define no user attribute.
+	ConnAttributeUser
+	// ConnAttributeDatabase adds the database name as db.name or
+	// db.namespace.
+	ConnAttributeDatabase
+	// ConnAttributeBackendPID adds the server process PID as pgx.backend_pid.
+	ConnAttributeBackendPID
+	// ConnAttributeServerVersion adds the server version as
+	// pgx.server_version.
+	ConnAttributeServerVersion
+	// ConnAttributeApplicationName adds the application_name of the
+	// connection as pgx.application_name.
+	ConnAttributeApplicationName
+	// ConnAttributeTLS adds whether the connection uses TLS as pgx.tls.
+	ConnAttributeTLS
+)
+
+// defaultConnAttributes are the connection attributes used unless
+// WithConnectionAttributes is specified.
+var defaultConnAttributes = []ConnAttribute{
+	ConnAttributeServer,
+	ConnAttributeUser,
+	ConnAttributeDatabase,
+}
+
+// connAttributesKey is the pgconn.PgConn custom data key under which the
+// connection attributes are cached, per Tracer, as several Tracers may share
+// a connection through a MultiTracer.
+const connAttributesKey = "github.com/piusalfred/otelpgx.connAttributes"
+
+// connAttributes returns the attributes describing conn. They are computed
+// once per connection, when it is established or when first needed, and
+// reused for every span.
+func (t *Tracer) connAttributes(conn *pgx.Conn) []attribute.KeyValue {
+	if conn == nil {
+		return nil
+	}
+
+	return t.pgConnAttributes(conn.PgConn(), func() *pgx.ConnConfig { return conn.Config() })
+}
+
+// pgConnAttributes returns the attributes describing pgConn, like
+// connAttributes, for the operations run with a pgconn.PgConn. config is
+// only called when the attributes are not cached yet.
+func (t *Tracer) pgConnAttributes(pgConn *pgconn.PgConn, config func() *pgx.ConnConfig) []attribute.KeyValue {
+	data := pgConn.CustomData()
+	cached, _ := data[connAttributesKey].(map[*Tracer][]attribute.KeyValue)
+	if attrs, ok := cached[t]; ok {
+		return attrs
+	}
+
+	attrs := t.connConfigAttributes(config())
+
+	for _, field := range t.connAttrFields {
+		switch field {
+		case ConnAttributeBackendPID:
+			attrs = append(attrs, BackendPIDKey.Int64(int64(pgConn.PID())))
+		case ConnAttributeServerVersion:
+			attrs = append(attrs, ServerVersionKey.String(pgConn.ParameterStatus("server_version")))
+		case ConnAttributeApplicationName:
+			attrs = append(attrs, ApplicationNameKey.String(pgConn.ParameterStatus("application_name")))
+		case ConnAttributeTLS:
+			_, isTLS := pgConn.Conn().(*tls.Conn)
+			attrs = append(attrs, TLSKey.Bool(isTLS))
+		}
+	}
+
+	if data != nil {
+		if cached == nil {
+			cached = make(map[*Tracer][]attribute.KeyValue)
+			data[connAttributesKey] = cached
+		}
+		cached[t] = attrs
+	}
+
+	return attrs
+}
+
+// pgConnConfig returns the config of pgConn as far as it can be told from the
+// connection itself, as pgconn.PgConn does not expose the config it was
+// established with: the server is its remote address, the user its
+// session_authorization and the database is unknown.
+func pgConnConfig(pgConn *pgconn.PgConn) *pgx.ConnConfig {
+	config := &pgx.ConnConfig{}
+	config.User = pgConn.ParameterStatus("session_authorization")
+
+	switch addr := pgConn.Conn().RemoteAddr().(type) {
+	case *net.TCPAddr:
+		config.Host = addr.IP.String()
+		config.Port = uint16(addr.Port)
+	case nil:
+	default:
+		config.Host = addr.String()
+	}
+
+	return config
+}
+
+// connConfigAttributes returns the attributes describing a connection which
+// can be derived from its config alone.
+func (t *Tracer) connConfigAttributes(config *pgx.ConnConfig) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	for _, field := range t.connAttrFields {
+		switch field {
+		case ConnAttributeServer:
+			attrs = append(attrs, t.semConvStability.serverAttributes(config)...)
+		case ConnAttributeUser:
+			attrs = append(attrs, t.semConvStability.userAttributes(config)...)
+		case ConnAttributeDatabase:
+			attrs = append(attrs, t.semConvStability.databaseAttributes(config)...)
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,95 @@
+package otelpgx
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+)
+
+func TestTracer_connConfigAttributes(t *testing.T) {
+	config, err := pgx.ParseConfig("postgres://app@db.example.com:6432/orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		tracer *Tracer
+		want   []attribute.KeyValue
+	}{
+		{
+			name:   "Default",
+			tracer: NewTracer(WithSemConvStability(SemConvStabilityOld)),
+			want: []attribute.KeyValue{
+				semconv.NetPeerName("db.example.com"),
+				semconv.NetPeerPort(6432),
+				semconv.DBUser("app"),
+				semconv.DBName("orders"),
+			},
+		},
+		{
+			name:   "Stable semantic conventions",
+			tracer: NewTracer(WithSemConvStability(SemConvStabilityNew)),
+			want: []attribute.KeyValue{
+				semconv.ServerAddress("db.example.com"),
+				semconv.ServerPort(6432),
+				DBNamespaceKey.String("orders"),
+			},
+		},
+		{
+			name: "Selected fields",
+			tracer: NewTracer(
+				WithSemConvStability(SemConvStabilityOld),
+				WithConnectionAttributes(ConnAttributeDatabase, ConnAttributeBackendPID),
+			),
+			want: []attribute.KeyValue{
+				semconv.DBName("orders"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := attribute.NewSet(tt.tracer.connConfigAttributes(config)...)
+			if want := attribute.NewSet(tt.want...); !got.Equals(&want) {
+				t.Errorf("connConfigAttributes() = %v, want %v", got.ToSlice(), want.ToSlice())
+			}
+		})
+	}
+}
+
+func TestTracer_connAttributesCache(t *testing.T) {
+	server := newTestServer(t)
+	conn := server.connect(t, server.connConfig(t))
+
+	old := NewTracer(WithSemConvStability(SemConvStabilityOld))
+	stable := NewTracer(WithSemConvStability(SemConvStabilityNew))
+
+	// The tracers of a MultiTracer alternate on the connection.
+	oldAttrs := old.connAttributes(conn)
+	stableAttrs := stable.connAttributes(conn)
+
+	tests := []struct {
+		name   string
+		tracer *Tracer
+		want   []attribute.KeyValue
+	}{
+		{name: "Old", tracer: old, want: oldAttrs},
+		{name: "New", tracer: stable, want: stableAttrs},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.tracer.connAttributes(conn)
+			if len(got) == 0 || &got[0] != &tt.want[0] {
+				t.Errorf("connAttributes() = %v, want the cached %v", got, tt.want)
+			}
+		})
+	}
+
+	if set := attribute.NewSet(oldAttrs...); set.HasValue(DBNamespaceKey) {
+		t.Errorf("the attributes of the tracers are mixed up: %v", oldAttrs)
+	}
+}
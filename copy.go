@@ -0,0 +1,156 @@
+package otelpgx
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// CopyColumnsKey represents the columns copied by CopyFrom.
+	CopyColumnsKey = attribute.Key("pgx.copy.columns")
+	// CopyBytesKey represents the number of bytes streamed to or from the
+	// server by a copy.
+	CopyBytesKey = attribute.Key("pgx.copy.bytes")
+	// CopyRowsPerSecondKey represents the throughput of a copy in rows per
+	// second.
+	CopyRowsPerSecondKey = attribute.Key("pgx.copy.rows_per_second")
+)
+
+type copyStartKey struct{}
+
+// copyAttributes returns the attributes describing the outcome of a copy
+// which started at start. bytes is negative when unknown.
+func copyAttributes(tag pgconn.CommandTag, bytes int64, start time.Time) []attribute.KeyValue {
+	rows := tag.RowsAffected()
+	attrs := []attribute.KeyValue{RowsAffectedKey.Int64(rows)}
+
+	if bytes >= 0 {
+		attrs = append(attrs, CopyBytesKey.Int64(bytes))
+	}
+
+	if elapsed := time.Since(start); !start.IsZero() && elapsed > 0 {
+		attrs = append(attrs, CopyRowsPerSecondKey.Float64(float64(rows)/elapsed.Seconds()))
+	}
+
+	return attrs
+}
+
+// CopyFrom runs the COPY ... FROM STDIN statement sql on conn with
+// conn.CopyFrom, reading the data from r, in a "copy_from" span recording
+// the rows and bytes copied. Copies run with pgconn.PgConn are not seen by
+// the pgx tracer interfaces. The span has the connection attributes of the
+// pgx.Conn conn belongs to, if it was established with the Tracer, see
+// pgConnConfig otherwise.
+func (t *Tracer) CopyFrom(ctx context.Context, conn *pgconn.PgConn, r io.Reader, sql string) (pgconn.CommandTag, error) {
+	ctx, start := t.startCopy(ctx, conn, OperationCopyFrom, sql)
+
+	counter := &countingReader{r: r}
+	tag, err := conn.CopyFrom(ctx, counter, sql)
+
+	t.endCopy(ctx, conn, tag, counter.n, start, err)
+
+	return tag, err
+}
+
+// CopyTo runs the COPY ... TO STDOUT statement sql on conn with conn.CopyTo,
+// writing the data to w, in a "copy_to" span recording the rows and bytes
+// copied. Copies run with pgconn.PgConn are not seen by the pgx tracer
+// interfaces. The span has the same connection attributes as in CopyFrom.
+func (t *Tracer) CopyTo(ctx context.Context, conn *pgconn.PgConn, w io.Writer, sql string) (pgconn.CommandTag, error) {
+	ctx, start := t.startCopy(ctx, conn, OperationCopyTo, sql)
+
+	counter := &countingWriter{w: w}
+	tag, err := conn.CopyTo(ctx, counter, sql)
+
+	t.endCopy(ctx, conn, tag, counter.n, start, err)
+
+	return tag, err
+}
+
+// startCopy starts the span of a copy run with a pgconn.PgConn.
+func (t *Tracer) startCopy(ctx context.Context, conn *pgconn.PgConn, kind OperationKind, sql string) (context.Context, time.Time) {
+	start := time.Now()
+
+	ctx = t.startOperation(ctx, "COPY", sql)
+
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		return ctx, start
+	}
+
+	if t.skipSpan(ctx, kind, sql) {
+		return withoutSpan(ctx), start
+	}
+
+	opts := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(t.attrs...),
+		trace.WithAttributes(t.pgConnAttributes(conn, func() *pgx.ConnConfig { return pgConnConfig(conn) })...),
+		trace.WithAttributes(timeoutAttributes(ctx, nil)...),
+	}
+
+	stmt := t.statement(sql)
+
+	if t.logSQLStatement {
+		opts = append(opts, trace.WithAttributes(t.semConvStability.statementAttributes(stmt)...))
+	}
+
+	spanName := string(kind) + " " + stmt
+	if t.trimQuerySpanName {
+		spanName = string(kind) + " " + t.sqlOperationName(stmt)
+	}
+
+	ctx, _ = t.tracer.Start(ctx, spanName, opts...)
+	t.pushPgConnNoticeSpan(ctx, conn)
+
+	return ctx, start
+}
+
+// endCopy ends the span of a copy run with a pgconn.PgConn.
+func (t *Tracer) endCopy(ctx context.Context, conn *pgconn.PgConn, tag pgconn.CommandTag, bytes int64, start time.Time, err error) {
+	op, elapsed, ok := t.endOperation(ctx, err)
+
+	span := trace.SpanFromContext(ctx)
+	t.recordError(ctx, span, err)
+
+	if ok {
+		t.detectSlowQuery(ctx, span, op, elapsed, err)
+	}
+
+	if err == nil {
+		span.SetAttributes(copyAttributes(tag, bytes, start)...)
+	}
+
+	t.popPgConnNoticeSpan(ctx, conn)
+
+	span.End()
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// countingWriter counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,158 @@
+package otelpgx
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+)
+
+func TestCopyAttributes(t *testing.T) {
+	tag := pgconn.NewCommandTag("COPY 1000")
+
+	tests := []struct {
+		name      string
+		bytes     int64
+		start     time.Time
+		wantBytes bool
+		wantRate  bool
+	}{
+		{name: "Unknown bytes", bytes: -1, start: time.Now().Add(-time.Second), wantRate: true},
+		{name: "Known bytes", bytes: 4096, start: time.Now().Add(-time.Second), wantBytes: true, wantRate: true},
+		{name: "Unknown start", bytes: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attrs := attribute.NewSet(copyAttributes(tag, tt.bytes, tt.start)...)
+
+			if rows, _ := attrs.Value(RowsAffectedKey); rows.AsInt64() != 1000 {
+				t.Errorf("%v = %v, want 1000", RowsAffectedKey, rows.Emit())
+			}
+			if got, ok := attrs.Value(CopyBytesKey); ok != tt.wantBytes || (ok && got.AsInt64() != tt.bytes) {
+				t.Errorf("%v = %v, %v, want %v", CopyBytesKey, got.Emit(), ok, tt.bytes)
+			}
+			rate, ok := attrs.Value(CopyRowsPerSecondKey)
+			if ok != tt.wantRate {
+				t.Errorf("%v present = %v, want %v", CopyRowsPerSecondKey, ok, tt.wantRate)
+			}
+			if ok && (rate.AsFloat64() <= 0 || rate.AsFloat64() > 1000) {
+				t.Errorf("%v = %v, want between 0 and 1000", CopyRowsPerSecondKey, rate.AsFloat64())
+			}
+		})
+	}
+}
+
+func TestCountingReaderWriter(t *testing.T) {
+	r := &countingReader{r: strings.NewReader("1\talice\n2\tbob\n")}
+	var buf bytes.Buffer
+	w := &countingWriter{w: &buf}
+
+	if _, err := io.Copy(w, r); err != nil {
+		t.Fatal(err)
+	}
+
+	if r.n != 14 || w.n != 14 {
+		t.Errorf("counted %d bytes read and %d bytes written, want 14", r.n, w.n)
+	}
+}
+
+func TestTracer_TraceCopyFrom(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tr := NewTracer(WithTracerProvider(tp))
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	ctx = tr.TraceCopyFromStart(ctx, nil, pgx.TraceCopyFromStartData{
+		TableName:   pgx.Identifier{"users"},
+		ColumnNames: []string{"id", "name"},
+	})
+	tr.TraceCopyFromEnd(ctx, nil, pgx.TraceCopyFromEndData{CommandTag: pgconn.NewCommandTag("COPY 2")})
+	parent.End()
+
+	attrs := attribute.NewSet(recorder.Ended()[0].Attributes()...)
+
+	if columns, _ := attrs.Value(CopyColumnsKey); strings.Join(columns.AsStringSlice(), ",") != "id,name" {
+		t.Errorf("%v = %v, want [id name]", CopyColumnsKey, columns.Emit())
+	}
+	if rows, _ := attrs.Value(RowsAffectedKey); rows.AsInt64() != 2 {
+		t.Errorf("%v = %v, want 2", RowsAffectedKey, rows.Emit())
+	}
+	if !attrs.HasValue(CopyRowsPerSecondKey) {
+		t.Errorf("missing attribute %v", CopyRowsPerSecondKey)
+	}
+	if attrs.HasValue(CopyBytesKey) {
+		t.Errorf("unexpected attribute %v", CopyBytesKey)
+	}
+}
+
+func TestTracer_CopyTo(t *testing.T) {
+	server := newTestServer(t)
+	addr := server.listener.Addr().(*net.TCPAddr)
+
+	tests := []struct {
+		name       string
+		traceConn  bool
+		wantDBName string
+	}{
+		{name: "Connected with the tracer", traceConn: true, wantDBName: "orders"},
+		{name: "Connected without the tracer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			tr := NewTracer(WithTracerProvider(tp), WithSemConvStability(SemConvStabilityOld))
+
+			config := server.connConfig(t)
+			if tt.traceConn {
+				config.Tracer = tr
+			}
+			conn := server.connect(t, config)
+
+			ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+			var buf bytes.Buffer
+			if _, err := tr.CopyTo(ctx, conn.PgConn(), &buf, "COPY users TO STDOUT"); err != nil {
+				t.Fatal(err)
+			}
+			parent.End()
+
+			var span sdktrace.ReadOnlySpan
+			for _, s := range recorder.Ended() {
+				if strings.HasPrefix(s.Name(), string(OperationCopyTo)) {
+					span = s
+				}
+			}
+			if span == nil {
+				t.Fatal("no copy_to span")
+			}
+
+			attrs := attribute.NewSet(span.Attributes()...)
+			for _, want := range []attribute.KeyValue{
+				semconv.DBSystemPostgreSQL,
+				semconv.NetPeerName(addr.IP.String()),
+				semconv.NetPeerPort(addr.Port),
+				semconv.DBUser("app"),
+				CopyBytesKey.Int64(int64(buf.Len())),
+			} {
+				if got, _ := attrs.Value(want.Key); got != want.Value {
+					t.Errorf("%v = %v, want %v", want.Key, got.Emit(), want.Value.Emit())
+				}
+			}
+			if got, _ := attrs.Value(semconv.DBNameKey); got.AsString() != tt.wantDBName {
+				t.Errorf("%v = %q, want %q", semconv.DBNameKey, got.AsString(), tt.wantDBName)
+			}
+		})
+	}
+}
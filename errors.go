@@ -0,0 +1,212 @@
+package otelpgx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// ErrorClassifier decides the span status of an operation which failed with
+// err, which is never nil. It returns false if it has no opinion about err,
+// in which case the next classifier is consulted. Operations classified as
+// codes.Error record the error on their span and count as failed in metrics,
+// the others do not.
+type ErrorClassifier func(err error) (codes.Code, bool)
+
+// ClassifyNoRows is an ErrorClassifier leaving the span status unset for
+// sql.ErrNoRows and pgx.ErrNoRows.
+func ClassifyNoRows(err error) (codes.Code, bool) {
+	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgx.ErrNoRows) {
+		return codes.Unset, true
+	}
+	return codes.Unset, false
+}
+
+// ClassifyCanceled is an ErrorClassifier leaving the span status unset for
+// operations aborted because their context was canceled.
+func ClassifyCanceled(err error) (codes.Code, bool) {
+	if errors.Is(err, context.Canceled) {
+		return codes.Unset, true
+	}
+	return codes.Unset, false
+}
+
+// ClassifySQLStates returns an ErrorClassifier leaving the span status unset
+// for the given SQLSTATE codes, such as 23505 for unique violations expected
+// by upserts.
+func ClassifySQLStates(sqlStates ...string) ErrorClassifier {
+	return func(err error) (codes.Code, bool) {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && slices.Contains(sqlStates, pgErr.Code) {
+			return codes.Unset, true
+		}
+		return codes.Unset, false
+	}
+}
+
+// ClassifySerializationFailures is an ErrorClassifier leaving the span status
+// unset for serialization failures (40001) and deadlocks (40P01), which are
+// usually retried.
+var ClassifySerializationFailures = ClassifySQLStates("40001", "40P01")
+
+// defaultErrorClassifiers are the classifiers used unless
+// WithErrorClassifiers is specified.
+var defaultErrorClassifiers = []ErrorClassifier{ClassifyNoRows}
+
+// classifyError returns the span status of an operation which ended with err.
+func (t *Tracer) classifyError(err error) codes.Code {
+	if err == nil {
+		return codes.Unset
+	}
+
+	for _, classify := range t.errorClassifiers {
+		if code, ok := classify(err); ok {
+			return code
+		}
+	}
+
+	return codes.Error
+}
+
+// ErrorTypeFunc returns the error.type attribute value of a failed operation.
+type ErrorTypeFunc func(err error) string
+
+// Values of the error.type attribute of errors which are not PostgreSQL
+// errors, see ErrorTypeSQLState.
+const (
+	// ErrorTypeCanceled is the type of errors caused by a canceled context.
+	ErrorTypeCanceled = "canceled"
+	// ErrorTypeDeadlineExceeded is the type of errors caused by an expired
+	// context.
+	ErrorTypeDeadlineExceeded = "deadline_exceeded"
+	// ErrorTypeConnect is the type of the errors establishing a connection.
+	ErrorTypeConnect = "connect"
+	// ErrorTypeTimeout is the type of the other timeouts, such as network
+	// timeouts.
+	ErrorTypeTimeout = "timeout"
+	// ErrorTypeOther is the type of the errors without a more specific type,
+	// as specified by the semantic conventions.
+	ErrorTypeOther = "_OTHER"
+)
+
+// ErrorTypeSQLState is an ErrorTypeFunc returning the SQLSTATE code of
+// PostgreSQL errors, such as 23505, and the type of other errors, see
+// goErrorType.
+func ErrorTypeSQLState(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code
+	}
+	return goErrorType(err)
+}
+
+// ErrorTypeSQLStateClass is an ErrorTypeFunc returning the name of the
+// SQLSTATE class of PostgreSQL errors, such as integrity_constraint_violation,
+// and the type of other errors, see goErrorType.
+func ErrorTypeSQLStateClass(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return sqlStateClassName(pgErr.Code)
+	}
+	return goErrorType(err)
+}
+
+// goErrorType returns the type of an error which is not a PostgreSQL error:
+// ErrorTypeCanceled, ErrorTypeDeadlineExceeded, ErrorTypeConnect or
+// ErrorTypeTimeout, else the Go type of the error wrapped by fmt.Errorf, such
+// as *net.OpError, or ErrorTypeOther for errors without a type of their own,
+// such as the ones returned by errors.New.
+func goErrorType(err error) string {
+	var connectErr *pgconn.ConnectError
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ErrorTypeCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorTypeDeadlineExceeded
+	case errors.As(err, &connectErr):
+		return ErrorTypeConnect
+	case pgconn.Timeout(err), errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorTypeTimeout
+	}
+
+	name := fmt.Sprintf("%T", err)
+	for name == "*fmt.wrapError" {
+		err = errors.Unwrap(err)
+		name = fmt.Sprintf("%T", err)
+	}
+
+	switch name {
+	case "*errors.errorString", "*errors.joinError", "*fmt.wrapErrors":
+		return ErrorTypeOther
+	default:
+		return name
+	}
+}
+
+// sqlStateClassNames maps SQLSTATE classes to their names,
+// see https://www.postgresql.org/docs/current/errcodes-appendix.html.
+var sqlStateClassNames = map[string]string{
+	"00": "successful_completion",
+	"01": "warning",
+	"02": "no_data",
+	"03": "sql_statement_not_yet_complete",
+	"08": "connection_exception",
+	"09": "triggered_action_exception",
+	"0A": "feature_not_supported",
+	"0B": "invalid_transaction_initiation",
+	"0F": "locator_exception",
+	"0L": "invalid_grantor",
+	"0P": "invalid_role_specification",
+	"0Z": "diagnostics_exception",
+	"20": "case_not_found",
+	"21": "cardinality_violation",
+	"22": "data_exception",
+	"23": "integrity_constraint_violation",
+	"24": "invalid_cursor_state",
+	"25": "invalid_transaction_state",
+	"26": "invalid_sql_statement_name",
+	"27": "triggered_data_change_violation",
+	"28": "invalid_authorization_specification",
+	"2B": "dependent_privilege_descriptors_still_exist",
+	"2D": "invalid_transaction_termination",
+	"2F": "sql_routine_exception",
+	"34": "invalid_cursor_name",
+	"38": "external_routine_exception",
+	"39": "external_routine_invocation_exception",
+	"3B": "savepoint_exception",
+	"3D": "invalid_catalog_name",
+	"3F": "invalid_schema_name",
+	"40": "transaction_rollback",
+	"42": "syntax_error_or_access_rule_violation",
+	"44": "with_check_option_violation",
+	"53": "insufficient_resources",
+	"54": "program_limit_exceeded",
+	"55": "object_not_in_prerequisite_state",
+	"57": "operator_intervention",
+	"58": "system_error",
+	"72": "snapshot_too_old",
+	"F0": "config_file_error",
+	"HV": "fdw_error",
+	"P0": "plpgsql_error",
+	"XX": "internal_error",
+}
+
+// sqlStateClassName returns the name of the class of the SQLSTATE code, or
+// the code itself if the class is unknown.
+func sqlStateClassName(code string) string {
+	if len(code) == 5 {
+		if name, ok := sqlStateClassNames[code[:2]]; ok {
+			return name
+		}
+	}
+	return code
+}
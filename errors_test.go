@@ -0,0 +1,108 @@
+package otelpgx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestTracer_classifyError(t *testing.T) {
+	uniqueViolation := &pgconn.PgError{Code: "23505"}
+	serializationFailure := &pgconn.PgError{Code: "40001"}
+
+	tests := []struct {
+		name   string
+		tracer *Tracer
+		err    error
+		want   codes.Code
+	}{
+		{name: "No error", tracer: NewTracer(), err: nil, want: codes.Unset},
+		{name: "sql.ErrNoRows", tracer: NewTracer(), err: sql.ErrNoRows, want: codes.Unset},
+		{name: "pgx.ErrNoRows", tracer: NewTracer(), err: fmt.Errorf("get user: %w", pgx.ErrNoRows), want: codes.Unset},
+		{name: "Other error", tracer: NewTracer(), err: errors.New("boom"), want: codes.Error},
+		{name: "Canceled by default", tracer: NewTracer(), err: context.Canceled, want: codes.Error},
+		{
+			name:   "Canceled",
+			tracer: NewTracer(WithErrorClassifiers(ClassifyCanceled)),
+			err:    context.Canceled,
+			want:   codes.Unset,
+		},
+		{
+			name:   "Expected SQL state",
+			tracer: NewTracer(WithErrorClassifiers(ClassifySQLStates("23505"))),
+			err:    uniqueViolation,
+			want:   codes.Unset,
+		},
+		{
+			name:   "Unexpected SQL state",
+			tracer: NewTracer(WithErrorClassifiers(ClassifySQLStates("23505"))),
+			err:    serializationFailure,
+			want:   codes.Error,
+		},
+		{
+			name:   "Serialization failure",
+			tracer: NewTracer(WithErrorClassifiers(ClassifySerializationFailures)),
+			err:    serializationFailure,
+			want:   codes.Unset,
+		},
+		{
+			name: "Custom classifier",
+			tracer: NewTracer(WithErrorClassifiers(func(err error) (codes.Code, bool) {
+				return codes.Ok, true
+			})),
+			err:  uniqueViolation,
+			want: codes.Ok,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tracer.classifyError(tt.err); got != tt.want {
+				t.Errorf("classifyError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorType(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   ErrorTypeFunc
+		err  error
+		want string
+	}{
+		{name: "SQL state", fn: ErrorTypeSQLState, err: &pgconn.PgError{Code: "23505"}, want: "23505"},
+		{name: "SQL state class", fn: ErrorTypeSQLStateClass, err: &pgconn.PgError{Code: "23505"}, want: "integrity_constraint_violation"},
+		{name: "Unknown SQL state class", fn: ErrorTypeSQLStateClass, err: &pgconn.PgError{Code: "ZZ000"}, want: "ZZ000"},
+		{name: "Wrapped SQL state", fn: ErrorTypeSQLState, err: fmt.Errorf("insert: %w", &pgconn.PgError{Code: "23505"}), want: "23505"},
+		{name: "Canceled", fn: ErrorTypeSQLState, err: fmt.Errorf("query: %w", context.Canceled), want: ErrorTypeCanceled},
+		{name: "Deadline exceeded", fn: ErrorTypeSQLStateClass, err: context.DeadlineExceeded, want: ErrorTypeDeadlineExceeded},
+		{name: "Connect", fn: ErrorTypeSQLState, err: &pgconn.ConnectError{Config: &pgconn.Config{}}, want: ErrorTypeConnect},
+		{name: "Network timeout", fn: ErrorTypeSQLState, err: &net.OpError{Op: "read", Err: timeoutError{}}, want: ErrorTypeTimeout},
+		{name: "Wrapped Go error", fn: ErrorTypeSQLState, err: fmt.Errorf("a: %w", fmt.Errorf("b: %w", &net.OpError{Op: "dial", Err: errors.New("refused")})), want: "*net.OpError"},
+		{name: "Anonymous error", fn: ErrorTypeSQLState, err: fmt.Errorf("query: %w", errors.New("boom")), want: ErrorTypeOther},
+		{name: "Joined errors", fn: ErrorTypeSQLState, err: errors.Join(errors.New("a"), errors.New("b")), want: ErrorTypeOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fn(tt.err); got != tt.want {
+				t.Errorf("error type = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// timeoutError is a net.Error timing out.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
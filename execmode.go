@@ -0,0 +1,106 @@
+package otelpgx
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// QueryExecModeKey represents the pgx.QueryExecMode a query was run with,
+	// such as cache_statement or simple_protocol.
+	QueryExecModeKey = attribute.Key("pgx.query.exec_mode")
+	// StatementCacheHitKey represents whether a query run in the
+	// cache_statement or cache_describe mode found its statement in the
+	// cache, rather than preparing it.
+	StatementCacheHitKey = attribute.Key("pgx.statement_cache.hit")
+	// PrepareImplicitKey represents whether a prepare was triggered by a
+	// query rather than by an explicit Prepare call.
+	PrepareImplicitKey = attribute.Key("pgx.prepare.implicit")
+	// PrepareAlreadyPreparedKey represents whether the statement was already
+	// prepared on the connection.
+	PrepareAlreadyPreparedKey = attribute.Key("pgx.prepare.already_prepared")
+)
+
+// connExecModeKey is the pgconn.PgConn custom data key under which the
+// default query execution mode of the connection is cached.
+const connExecModeKey = "github.com/piusalfred/otelpgx.defaultQueryExecMode"
+
+// connDefaultQueryExecMode returns the default query execution mode of conn.
+func connDefaultQueryExecMode(conn *pgx.Conn) pgx.QueryExecMode {
+	data := conn.PgConn().CustomData()
+	if mode, ok := data[connExecModeKey].(pgx.QueryExecMode); ok {
+		return mode
+	}
+
+	mode := conn.Config().DefaultQueryExecMode
+	if data != nil {
+		data[connExecModeKey] = mode
+	}
+
+	return mode
+}
+
+// queryExecMode returns the execution mode requested for a query with the
+// given arguments, and whether the query has arguments other than query
+// options. pgx may still use the simple protocol for statements without
+// arguments.
+func queryExecMode(conn *pgx.Conn, args []any) (mode pgx.QueryExecMode, ok bool, hasArgs bool) {
+	if conn != nil {
+		mode, ok = connDefaultQueryExecMode(conn), true
+	}
+
+	for len(args) > 0 {
+		switch arg := args[0].(type) {
+		case pgx.QueryExecMode:
+			mode, ok = arg, true
+		case sqlCommentRewriter:
+			// The comment adds no arguments, unlike the rewriter it wraps.
+			hasArgs = hasArgs || arg.inner != nil
+		case pgx.QueryRewriter:
+			// The rewriter may turn it into arguments.
+			hasArgs = true
+		case pgx.QueryResultFormats, pgx.QueryResultFormatsByOID:
+		default:
+			return mode, ok, true
+		}
+		args = args[1:]
+	}
+
+	return mode, ok, hasArgs
+}
+
+// execModeName returns the snake case name of mode, such as cache_statement.
+func execModeName(mode pgx.QueryExecMode) string {
+	return strings.ReplaceAll(mode.String(), " ", "_")
+}
+
+func cachesStatements(mode pgx.QueryExecMode) bool {
+	return mode == pgx.QueryExecModeCacheStatement || mode == pgx.QueryExecModeCacheDescribe
+}
+
+type queryStateKey struct{}
+
+// queryState is stored in the context by TraceQueryStart so that
+// TracePrepareStart can tell the prepares triggered by the query: pgx only
+// passes the context of a query to the prepares it runs on its behalf.
+type queryState struct {
+	// cachesStatement is set when the query is run in a mode caching its
+	// statement, and prepared when the query prepared its statement.
+	cachesStatement bool
+	prepared        bool
+}
+
+// markImplicitPrepare reports whether the prepare traced in ctx was triggered
+// by a query, and records it on the query.
+func markImplicitPrepare(ctx context.Context) bool {
+	state, ok := ctx.Value(queryStateKey{}).(*queryState)
+	if !ok {
+		return false
+	}
+
+	state.prepared = true
+	return true
+}
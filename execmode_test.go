@@ -0,0 +1,126 @@
+package otelpgx
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracer_queryExecMode(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tr := NewTracer(WithTracerProvider(tp))
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	defer parent.End()
+
+	sql := "SELECT * FROM users WHERE id = $1"
+
+	// A query preparing its statement, as pgx does on a statement cache miss.
+	queryCtx := tr.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: sql, Args: []any{pgx.QueryExecModeCacheStatement, 1}})
+	prepareCtx := tr.TracePrepareStart(queryCtx, nil, pgx.TracePrepareStartData{Name: "stmt_1", SQL: sql})
+	tr.TracePrepareEnd(prepareCtx, nil, pgx.TracePrepareEndData{})
+	tr.TraceQueryEnd(queryCtx, nil, pgx.TraceQueryEndData{})
+
+	// A query finding its statement in the cache.
+	queryCtx = tr.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: sql, Args: []any{pgx.QueryExecModeCacheStatement, 1}})
+	tr.TraceQueryEnd(queryCtx, nil, pgx.TraceQueryEndData{})
+
+	// An explicit prepare.
+	prepareCtx = tr.TracePrepareStart(ctx, nil, pgx.TracePrepareStartData{Name: "get_user", SQL: sql})
+	tr.TracePrepareEnd(prepareCtx, nil, pgx.TracePrepareEndData{AlreadyPrepared: true})
+
+	spans := recorder.Ended()
+	if len(spans) != 4 {
+		t.Fatalf("got %d ended spans, want 4", len(spans))
+	}
+
+	want := []map[attribute.Key]attribute.Value{
+		{
+			PrepareStmtNameKey:        attribute.StringValue("stmt_1"),
+			PrepareImplicitKey:        attribute.BoolValue(true),
+			PrepareAlreadyPreparedKey: attribute.BoolValue(false),
+		},
+		{
+			QueryExecModeKey:     attribute.StringValue("cache_statement"),
+			StatementCacheHitKey: attribute.BoolValue(false),
+		},
+		{
+			QueryExecModeKey:     attribute.StringValue("cache_statement"),
+			StatementCacheHitKey: attribute.BoolValue(true),
+		},
+		{
+			PrepareStmtNameKey:        attribute.StringValue("get_user"),
+			PrepareImplicitKey:        attribute.BoolValue(false),
+			PrepareAlreadyPreparedKey: attribute.BoolValue(true),
+		},
+	}
+
+	for i, span := range spans {
+		attrs := attribute.NewSet(span.Attributes()...)
+		for key, v := range want[i] {
+			if got, _ := attrs.Value(key); got != v {
+				t.Errorf("span %q: %v = %v, want %v", span.Name(), key, got.Emit(), v.Emit())
+			}
+		}
+	}
+
+	if spans[0].Parent().SpanID() != spans[1].SpanContext().SpanID() {
+		t.Errorf("the implicit prepare is not a child of its query")
+	}
+}
+
+func TestTracer_queryExecModeQuerier(t *testing.T) {
+	server := newTestServer(t)
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tr := NewTracer(WithTracerProvider(tp), WithSQLCommenter())
+
+	config := server.connConfig(t)
+	config.Tracer = tr
+	conn := server.connect(t, config)
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	defer parent.End()
+
+	q := tr.Querier(conn)
+
+	// pgx runs the statements without arguments with the simple protocol.
+	if _, err := q.Exec(ctx, "BEGIN"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.Exec(ctx, "SELECT * FROM users WHERE id = @id", pgx.NamedArgs{"id": "1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]bool{
+		"query BEGIN": false,
+		"query SELECT * FROM users WHERE id = @id": true,
+	}
+
+	for _, span := range recorder.Ended() {
+		wantHit, ok := want[span.Name()]
+		if !ok {
+			continue
+		}
+		delete(want, span.Name())
+
+		attrs := attribute.NewSet(span.Attributes()...)
+		if _, got := attrs.Value(StatementCacheHitKey); got != wantHit {
+			t.Errorf("span %q: has %v = %v, want %v", span.Name(), StatementCacheHitKey, got, wantHit)
+		}
+	}
+	if len(want) > 0 {
+		t.Errorf("missing spans %v", want)
+	}
+
+	if stmts := server.received(); len(stmts) == 0 || !strings.HasPrefix(stmts[0], "BEGIN") {
+		t.Errorf("statements received = %q, want BEGIN first", stmts)
+	}
+}
@@ -0,0 +1,106 @@
+package otelpgx
+
+import (
+	"context"
+
+	"github.com/piusalfred/otelpgx/internal/sqltoken"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// OperationKind is the kind of operation a span is started for.
+type OperationKind string
+
+// Kinds of operations passed to a SpanFilter.
+const (
+	OperationQuery      OperationKind = "query"
+	OperationBatchQuery OperationKind = "batch query"
+	OperationPrepare    OperationKind = "prepare"
+	OperationCopyFrom   OperationKind = "copy_from"
+	OperationCopyTo     OperationKind = "copy_to"
+	OperationConnect    OperationKind = "connect"
+)
+
+// SpanFilter reports whether a span is started for an operation. sql is the
+// statement for queries, batch queries and prepares, the table name for
+// pgx.Conn.CopyFrom copies, the COPY statement for the copies run with
+// Tracer.CopyFrom and Tracer.CopyTo, and empty for connects. Operation
+// durations are recorded whether or not a span is started.
+type SpanFilter func(ctx context.Context, kind OperationKind, sql string) bool
+
+// SkipPingQueries is a SpanFilter skipping empty statements and health
+// check queries such as SELECT 1.
+func SkipPingQueries(_ context.Context, kind OperationKind, sql string) bool {
+	if kind == OperationCopyFrom || kind == OperationCopyTo || kind == OperationConnect {
+		return true
+	}
+
+	tokens := statementTokens(sql)
+	switch {
+	case len(tokens) == 0:
+		return false
+	case len(tokens) == 2 && tokens[0].IsKeyword("SELECT"):
+		return !tokens[1].IsLiteral() && !tokens[1].IsKeyword("TRUE")
+	default:
+		return true
+	}
+}
+
+// SkipTransactionControl is a SpanFilter skipping transaction control
+// statements such as BEGIN, COMMIT, ROLLBACK and SAVEPOINT.
+func SkipTransactionControl(_ context.Context, kind OperationKind, sql string) bool {
+	return !isStatementOf(kind, sql, "BEGIN", "START", "COMMIT", "END", "ROLLBACK", "ABORT", "SAVEPOINT", "RELEASE")
+}
+
+// SkipSessionStatements is a SpanFilter skipping the SET, SHOW, RESET and
+// DISCARD session statements.
+func SkipSessionStatements(_ context.Context, kind OperationKind, sql string) bool {
+	return !isStatementOf(kind, sql, "SET", "SHOW", "RESET", "DISCARD")
+}
+
+// isStatementOf reports whether sql is a statement starting with one of the
+// keywords.
+func isStatementOf(kind OperationKind, sql string, keywords ...string) bool {
+	if kind == OperationCopyFrom || kind == OperationCopyTo || kind == OperationConnect {
+		return false
+	}
+
+	tokens := statementTokens(sql)
+	if len(tokens) == 0 {
+		return false
+	}
+
+	for _, kw := range keywords {
+		if tokens[0].IsKeyword(kw) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// statementTokens returns the tokens of sql without whitespace, comments
+// and trailing semicolons.
+func statementTokens(sql string) []sqltoken.Token {
+	tokens := significantTokens(sqltoken.Tokenize(sql))
+	for len(tokens) > 0 && tokens[len(tokens)-1].Text == ";" {
+		tokens = tokens[:len(tokens)-1]
+	}
+	return tokens
+}
+
+// skipSpan reports whether one of the filters rejects the operation.
+func (t *Tracer) skipSpan(ctx context.Context, kind OperationKind, sql string) bool {
+	for _, filter := range t.spanFilters {
+		if !filter(ctx, kind, sql) {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutSpan returns ctx with a non-recording span in place of the current
+// span, so that the matching Trace*End method does not end the caller's span
+// while nested operations keep the caller's span as parent.
+func withoutSpan(ctx context.Context) context.Context {
+	return trace.ContextWithSpanContext(ctx, trace.SpanContextFromContext(ctx))
+}
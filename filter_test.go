@@ -0,0 +1,69 @@
+package otelpgx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSpanFilters(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter SpanFilter
+		kind   OperationKind
+		sql    string
+		want   bool
+	}{
+		{name: "Ping", filter: SkipPingQueries, kind: OperationQuery, sql: "SELECT 1", want: false},
+		{name: "Ping with semicolon", filter: SkipPingQueries, kind: OperationQuery, sql: "select 1;", want: false},
+		{name: "Empty statement", filter: SkipPingQueries, kind: OperationQuery, sql: " -- ping", want: false},
+		{name: "Not a ping", filter: SkipPingQueries, kind: OperationQuery, sql: "SELECT id FROM users", want: true},
+		{name: "Ping filter on connect", filter: SkipPingQueries, kind: OperationConnect, want: true},
+		{name: "Ping filter on copy to", filter: SkipPingQueries, kind: OperationCopyTo, sql: "COPY users TO STDOUT", want: true},
+		{name: "Begin", filter: SkipTransactionControl, kind: OperationQuery, sql: "begin", want: false},
+		{name: "Begin isolation level", filter: SkipTransactionControl, kind: OperationQuery, sql: "begin isolation level serializable", want: false},
+		{name: "Savepoint", filter: SkipTransactionControl, kind: OperationQuery, sql: "savepoint sp_1", want: false},
+		{name: "Commit", filter: SkipTransactionControl, kind: OperationBatchQuery, sql: "COMMIT", want: false},
+		{name: "Not transaction control", filter: SkipTransactionControl, kind: OperationQuery, sql: "SELECT 1", want: true},
+		{name: "Set", filter: SkipSessionStatements, kind: OperationQuery, sql: "SET search_path TO app", want: false},
+		{name: "Discard", filter: SkipSessionStatements, kind: OperationQuery, sql: "DISCARD ALL", want: false},
+		{name: "Not a session statement", filter: SkipSessionStatements, kind: OperationPrepare, sql: "UPDATE t SET a = 1", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter(context.Background(), tt.kind, tt.sql); got != tt.want {
+				t.Errorf("filter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTracer_spanFilter(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tr := NewTracer(WithTracerProvider(tp), WithSpanFilter(SkipPingQueries))
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+
+	for _, sql := range []string{"SELECT 1", "SELECT * FROM users"} {
+		queryCtx := tr.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: sql})
+		tr.TraceQueryEnd(queryCtx, nil, pgx.TraceQueryEndData{})
+	}
+
+	if !parent.IsRecording() {
+		t.Fatal("the filtered query ended the parent span")
+	}
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d ended spans, want 2", len(spans))
+	}
+	if spans[0].Name() != "query SELECT * FROM users" {
+		t.Errorf("unexpected span %q", spans[0].Name())
+	}
+}
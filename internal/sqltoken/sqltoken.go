@@ -0,0 +1,278 @@
+// Package sqltoken splits PostgreSQL statements into tokens.
+//
+// The tokenizer is lenient: it never fails, and unterminated strings,
+// identifiers or comments extend to the end of the input.
+package sqltoken
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Kind is the kind of a Token.
+type Kind int
+
+const (
+	// Whitespace is a run of whitespace.
+	Whitespace Kind = iota
+	// Comment is a -- line comment or a, possibly nested, /* block comment */.
+	Comment
+	// Ident is an unquoted identifier or keyword.
+	Ident
+	// QuotedIdent is a "quoted identifier", including U&"..." identifiers.
+	QuotedIdent
+	// String is a string constant: '...', E'...', U&'...', B'...' or X'...'.
+	String
+	// DollarString is a $tag$...$tag$ dollar-quoted string constant.
+	DollarString
+	// Number is a numeric constant, including 0x, 0o and 0b integers.
+	Number
+	// Param is a positional parameter such as $1.
+	Param
+	// Operator is an operator, including the :: cast operator.
+	Operator
+	// Punct is one of the characters ( ) [ ] , ; . :
+	Punct
+)
+
+// Token is a piece of a statement.
+type Token struct {
+	Kind Kind
+	Text string
+}
+
+// IsLiteral reports whether the token is a constant.
+func (t Token) IsLiteral() bool {
+	return t.Kind == String || t.Kind == DollarString || t.Kind == Number
+}
+
+// IsKeyword reports whether the token is the unquoted keyword kw, compared
+// case-insensitively.
+func (t Token) IsKeyword(kw string) bool {
+	return t.Kind == Ident && strings.EqualFold(t.Text, kw)
+}
+
+// Tokenize splits sql into tokens. Concatenating the text of the returned
+// tokens yields sql.
+func Tokenize(sql string) []Token {
+	var tokens []Token
+	for len(sql) > 0 {
+		kind, n := next(sql)
+		tokens = append(tokens, Token{Kind: kind, Text: sql[:n]})
+		sql = sql[n:]
+	}
+	return tokens
+}
+
+// next returns the kind and length of the token at the start of s.
+func next(s string) (Kind, int) {
+	c := s[0]
+
+	switch {
+	case isSpace(c):
+		n := 1
+		for n < len(s) && isSpace(s[n]) {
+			n++
+		}
+		return Whitespace, n
+
+	case strings.HasPrefix(s, "--"):
+		n := strings.IndexByte(s, '\n')
+		if n < 0 {
+			return Comment, len(s)
+		}
+		return Comment, n
+
+	case strings.HasPrefix(s, "/*"):
+		return Comment, blockComment(s)
+
+	case c == '\'':
+		return String, quoted(s, 0, '\'', false)
+
+	case (c == 'e' || c == 'E') && len(s) > 1 && s[1] == '\'':
+		return String, quoted(s, 1, '\'', true)
+
+	case (c == 'b' || c == 'B' || c == 'x' || c == 'X') && len(s) > 1 && s[1] == '\'':
+		return String, quoted(s, 1, '\'', false)
+
+	case (c == 'u' || c == 'U') && len(s) > 2 && s[1] == '&' && s[2] == '\'':
+		return String, quoted(s, 2, '\'', false)
+
+	case (c == 'u' || c == 'U') && len(s) > 2 && s[1] == '&' && s[2] == '"':
+		return QuotedIdent, quoted(s, 2, '"', false)
+
+	case c == '"':
+		return QuotedIdent, quoted(s, 0, '"', false)
+
+	case c == '$':
+		return dollar(s)
+
+	case isDigit(c) || (c == '.' && len(s) > 1 && isDigit(s[1])):
+		return Number, number(s)
+
+	case isIdentStart(s):
+		return Ident, ident(s)
+
+	case strings.HasPrefix(s, "::"):
+		return Operator, 2
+
+	case strings.IndexByte("()[],;.:", c) >= 0:
+		return Punct, 1
+
+	case strings.IndexByte(operatorChars, c) >= 0:
+		n := 1
+		for n < len(s) && strings.IndexByte(operatorChars, s[n]) >= 0 &&
+			!strings.HasPrefix(s[n:], "--") && !strings.HasPrefix(s[n:], "/*") {
+			n++
+		}
+		return Operator, n
+	}
+
+	// Anything else is passed through one rune at a time.
+	_, n := utf8.DecodeRuneInString(s)
+	return Operator, n
+}
+
+const operatorChars = "+-*/<>=~!@#%^&|`?"
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isIdentStart(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentPart(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// ident returns the length of the identifier at the start of s.
+func ident(s string) int {
+	n := 0
+	for n < len(s) && isIdentPart(s[n:]) {
+		_, size := utf8.DecodeRuneInString(s[n:])
+		n += size
+	}
+	return n
+}
+
+// blockComment returns the length of the block comment at the start of s.
+// Block comments nest in PostgreSQL.
+func blockComment(s string) int {
+	depth := 0
+	for n := 0; n < len(s); {
+		switch {
+		case strings.HasPrefix(s[n:], "/*"):
+			depth++
+			n += 2
+		case strings.HasPrefix(s[n:], "*/"):
+			depth--
+			n += 2
+			if depth == 0 {
+				return n
+			}
+		default:
+			n++
+		}
+	}
+	return len(s)
+}
+
+// quoted returns the length of the quoted text starting with q at s[start].
+// A doubled quote stands for itself, and when backslash is set a backslash
+// escapes the following character.
+func quoted(s string, start int, q byte, backslash bool) int {
+	for n := start + 1; n < len(s); n++ {
+		switch s[n] {
+		case '\\':
+			if backslash {
+				n++
+			}
+		case q:
+			if n+1 < len(s) && s[n+1] == q {
+				n++
+				continue
+			}
+			return n + 1
+		}
+	}
+	return len(s)
+}
+
+// dollar returns the kind and length of the parameter or dollar-quoted
+// string at the start of s.
+func dollar(s string) (Kind, int) {
+	n := 1
+	for n < len(s) && isDigit(s[n]) {
+		n++
+	}
+	if n > 1 {
+		return Param, n
+	}
+
+	// The tag follows the identifier rules, except that it cannot contain a
+	// dollar sign.
+	if n < len(s) && isIdentStart(s[n:]) {
+		for n < len(s) && s[n] != '$' && isIdentPart(s[n:]) {
+			_, size := utf8.DecodeRuneInString(s[n:])
+			n += size
+		}
+	}
+	if n >= len(s) || s[n] != '$' {
+		return Operator, 1
+	}
+
+	tag := s[:n+1]
+	end := strings.Index(s[len(tag):], tag)
+	if end < 0 {
+		return DollarString, len(s)
+	}
+	return DollarString, len(tag) + end + len(tag)
+}
+
+// number returns the length of the numeric constant at the start of s.
+func number(s string) int {
+	if len(s) > 2 && s[0] == '0' && strings.IndexByte("xXoObB", s[1]) >= 0 {
+		n := 2
+		for n < len(s) && (isHexDigit(s[n]) || s[n] == '_') {
+			n++
+		}
+		return n
+	}
+
+	n := 0
+	for n < len(s) && (isDigit(s[n]) || s[n] == '_') {
+		n++
+	}
+	if n < len(s) && s[n] == '.' && !strings.HasPrefix(s[n:], "..") {
+		n++
+		for n < len(s) && (isDigit(s[n]) || s[n] == '_') {
+			n++
+		}
+	}
+	if n < len(s) && (s[n] == 'e' || s[n] == 'E') {
+		m := n + 1
+		if m < len(s) && (s[m] == '+' || s[m] == '-') {
+			m++
+		}
+		if m < len(s) && isDigit(s[m]) {
+			n = m
+			for n < len(s) && isDigit(s[n]) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
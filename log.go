@@ -7,6 +7,7 @@ import (
 	"slices"
 
 	"github.com/jackc/pgx/v5/tracelog"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -21,10 +22,13 @@ var LevelNames = map[slog.Level]string{
 
 type (
 	Logger struct {
-		logger     *slog.Logger
-		converter  LogLevelConverter
-		level      slog.Level
-		isLevelSet bool
+		logger       *slog.Logger
+		converter    LogLevelConverter
+		level        slog.Level
+		isLevelSet   bool
+		traceContext bool
+		extractors   []ContextExtractor
+		renderer     LogDataRenderer
 	}
 
 	LogLevelConverter interface {
@@ -35,8 +39,44 @@ type (
 	defaultLogLevelConverter struct{}
 
 	LoggerOption func(*Logger)
+
+	// ContextExtractor returns attributes to add to the log records of the
+	// operations run with ctx, such as a request ID or a tenant.
+	ContextExtractor func(ctx context.Context) []slog.Attr
 )
 
+// WithTraceContext adds the trace_id, span_id and trace_flags attributes of
+// the span in the context of the logged operation, if any, to log records.
+func WithTraceContext() LoggerOption {
+	return func(l *Logger) {
+		l.traceContext = true
+	}
+}
+
+// WithContextExtractor adds the attributes returned by extractor to log
+// records.
+func WithContextExtractor(extractor ContextExtractor) LoggerOption {
+	return func(l *Logger) {
+		if extractor != nil {
+			l.extractors = append(l.extractors, extractor)
+		}
+	}
+}
+
+// traceContextAttrs returns the attributes identifying the span in ctx.
+func traceContextAttrs(ctx context.Context) []slog.Attr {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+
+	return []slog.Attr{
+		slog.String("trace_id", sc.TraceID().String()),
+		slog.String("span_id", sc.SpanID().String()),
+		slog.String("trace_flags", sc.TraceFlags().String()),
+	}
+}
+
 // WithLogLevelConverter sets the log level converter.
 func WithLogLevelConverter(c LogLevelConverter) LoggerOption {
 	return func(l *Logger) {
@@ -148,10 +188,23 @@ func (l Logger) determineLogLevel(ctx context.Context, levels []slog.Level) slog
 func (l Logger) Log(ctx context.Context, level tracelog.LogLevel, msg string, data map[string]any) {
 	ll := l.converter.ToSlogLevel(level).Level()
 
-	attrs := make([]slog.Attr, 0, len(data))
+	var attrs []slog.Attr
+
+	if l.renderer != nil {
+		attrs = l.renderer(data)
+	} else {
+		attrs = make([]slog.Attr, 0, len(data))
+		for k, v := range data {
+			attrs = append(attrs, slog.Any(k, v))
+		}
+	}
+
+	if l.traceContext {
+		attrs = append(attrs, traceContextAttrs(ctx)...)
+	}
 
-	for k, v := range data {
-		attrs = append(attrs, slog.Any(k, v))
+	for _, extract := range l.extractors {
+		attrs = append(attrs, extract(ctx)...)
 	}
 
 	l.logger.LogAttrs(ctx, ll, msg, attrs...)
@@ -1,12 +1,15 @@
 package otelpgx
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"log/slog"
 	"os"
 	"testing"
 
 	"github.com/jackc/pgx/v5/tracelog"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestLogger_determineLogLevel(t *testing.T) {
@@ -105,3 +108,75 @@ func TestNewTraceLogger(t *testing.T) {
 		})
 	}
 }
+
+func TestLogger_LogTraceContext(t *testing.T) {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x01},
+		SpanID:     trace.SpanID{0x02},
+		TraceFlags: trace.FlagsSampled,
+	})
+	spanCtx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	tenant := func(context.Context) []slog.Attr {
+		return []slog.Attr{slog.String("tenant", "acme")}
+	}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		opts []LoggerOption
+		want map[string]string
+	}{
+		{
+			name: "Disabled",
+			ctx:  spanCtx,
+			want: map[string]string{"trace_id": "", "span_id": "", "tenant": ""},
+		},
+		{
+			name: "Trace context",
+			ctx:  spanCtx,
+			opts: []LoggerOption{WithTraceContext()},
+			want: map[string]string{
+				"trace_id":    sc.TraceID().String(),
+				"span_id":     sc.SpanID().String(),
+				"trace_flags": "01",
+			},
+		},
+		{
+			name: "No span",
+			ctx:  context.Background(),
+			opts: []LoggerOption{WithTraceContext()},
+			want: map[string]string{"trace_id": "", "span_id": ""},
+		},
+		{
+			name: "Context extractor",
+			ctx:  context.Background(),
+			opts: []LoggerOption{WithContextExtractor(tenant)},
+			want: map[string]string{"tenant": "acme"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			opts := append([]LoggerOption{
+				WithLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))),
+			}, tt.opts...)
+
+			l := newLogger(opts...)
+			l.Log(tt.ctx, tracelog.LogLevelInfo, "Query", map[string]any{"sql": "SELECT 1"})
+
+			var record map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+				t.Fatal(err)
+			}
+
+			for key, want := range tt.want {
+				got, _ := record[key].(string)
+				if got != want {
+					t.Errorf("%s = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package otelpgx
+
+import (
+	"errors"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+)
+
+// CommandTagKey represents the command tag returned by the server, such as
+// INSERT 0 1.
+const CommandTagKey = attribute.Key("pgx.command_tag")
+
+// logDataGroup is the group holding the pgx attributes rendered by
+// NewLogDataRenderer.
+const logDataGroup = "pgx"
+
+// LogDataRenderer renders the data pgx passes to Logger.Log, such as sql,
+// args and time, as log attributes.
+type LogDataRenderer func(data map[string]any) []slog.Attr
+
+// WithLogDataRenderer sets the LogDataRenderer of the logger. By default, each
+// data entry is logged as is, in no particular order.
+func WithLogDataRenderer(renderer LogDataRenderer) LoggerOption {
+	return func(l *Logger) {
+		l.renderer = renderer
+	}
+}
+
+// LogDataOption configures the LogDataRenderer returned by
+// NewLogDataRenderer.
+type LogDataOption func(*logDataRenderer)
+
+// WithLogSemConvStability specifies the semantic conventions used to name
+// the rendered attributes, such as db.statement or db.query.text. By default,
+// it is read from the OTEL_SEMCONV_STABILITY_OPT_IN environment variable.
+func WithLogSemConvStability(stability SemConvStability) LogDataOption {
+	return func(r *logDataRenderer) {
+		r.semConvStability = stability
+	}
+}
+
+// WithLogQueryParameterFormatter specifies how query arguments are rendered.
+// By default, they are rendered by NewParamFormatter(256).
+func WithLogQueryParameterFormatter(formatter ParamFormatter) LogDataOption {
+	return func(r *logDataRenderer) {
+		if formatter != nil {
+			r.paramFormatter = formatter
+		}
+	}
+}
+
+// WithLogQueryParameterRedactor replaces the query arguments for which
+// redactor returns true with [REDACTED], see RedactParams.
+func WithLogQueryParameterRedactor(redactor ParamRedactor) LogDataOption {
+	return func(r *logDataRenderer) {
+		r.paramRedactor = redactor
+	}
+}
+
+// WithoutLogArgs drops the query arguments from the rendered attributes.
+func WithoutLogArgs() LogDataOption {
+	return func(r *logDataRenderer) {
+		r.dropArgs = true
+	}
+}
+
+type logDataRenderer struct {
+	semConvStability SemConvStability
+	paramFormatter   ParamFormatter
+	paramRedactor    ParamRedactor
+	dropArgs         bool
+}
+
+// NewLogDataRenderer returns a LogDataRenderer which renders the data of pgx
+// sorted by key. Known keys are renamed after the attributes of the tracer:
+// the semantic convention ones, such as db.statement or server.address, keep
+// their names and are rendered at the top level, so they match those of the
+// spans, while the pgx. ones are rendered under a pgx group, with the prefix
+// trimmed:
+//   - sql becomes db.statement or db.query.text,
+//   - args becomes query.parameters, rendered like WithIncludeQueryParameters,
+//     except that tracelog.TraceLog has already hex-encoded byte slices and
+//     truncated the arguments longer than 64 bytes, so they are formatted as
+//     the strings they became,
+//   - time becomes duration_ms,
+//   - rowCount becomes rows_affected,
+//   - commandTag becomes command_tag,
+//   - err becomes error, exception.message and, for PostgreSQL errors,
+//     sql_state,
+//   - pid becomes backend_pid,
+//   - name becomes prepare_stmt.name,
+//   - alreadyPrepared becomes prepare.already_prepared,
+//   - tableName becomes db.sql.table or db.collection.name,
+//   - columnNames becomes copy.columns,
+//   - host, port and database become the server and database attributes.
+//
+// Other keys are kept under the pgx group, with durations rendered in
+// milliseconds.
+func NewLogDataRenderer(opts ...LogDataOption) LogDataRenderer {
+	r := &logDataRenderer{
+		semConvStability: semConvStabilityFromEnv(),
+		paramFormatter:   NewParamFormatter(defaultParamMaxLength),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r.render
+}
+
+// render implements LogDataRenderer.
+func (r *logDataRenderer) render(data map[string]any) []slog.Attr {
+	var kvs []attribute.KeyValue
+	var attrs []slog.Attr
+	group := make([]slog.Attr, 0, len(data))
+
+	for key, value := range data {
+		switch v := value.(type) {
+		case nil:
+			continue
+		case time.Duration:
+			if key == "time" {
+				kvs = append(kvs, DurationKey.Float64(milliseconds(v)))
+				continue
+			}
+		}
+
+		switch key {
+		case "sql":
+			kvs = append(kvs, r.semConvStability.statementAttributes(stringValue(value))...)
+		case "args":
+			if r.dropArgs {
+				continue
+			}
+			args, _ := value.([]any)
+			kvs = append(kvs, QueryParametersKey.StringSlice(formatParams(args, r.paramFormatter, r.paramRedactor)))
+		case "rowCount":
+			if n, ok := value.(int64); ok {
+				kvs = append(kvs, RowsAffectedKey.Int64(n))
+				continue
+			}
+			group = append(group, logDataAttr(key, value))
+		case "commandTag":
+			kvs = append(kvs, CommandTagKey.String(stringValue(value)))
+		case "err":
+			kvs = append(kvs, errorLogAttributes(value)...)
+		case "pid":
+			if pid, ok := value.(uint32); ok {
+				kvs = append(kvs, BackendPIDKey.Int64(int64(pid)))
+				continue
+			}
+			group = append(group, logDataAttr(key, value))
+		case "name":
+			kvs = append(kvs, PrepareStmtNameKey.String(stringValue(value)))
+		case "alreadyPrepared":
+			if prepared, ok := value.(bool); ok {
+				kvs = append(kvs, PrepareAlreadyPreparedKey.Bool(prepared))
+				continue
+			}
+			group = append(group, logDataAttr(key, value))
+		case "tableName":
+			if table, ok := value.(pgx.Identifier); ok {
+				kvs = append(kvs, r.semConvStability.tableAttributes(table.Sanitize())...)
+				continue
+			}
+			group = append(group, logDataAttr(key, value))
+		case "columnNames":
+			if columns, ok := value.([]string); ok {
+				kvs = append(kvs, CopyColumnsKey.StringSlice(columns))
+				continue
+			}
+			group = append(group, logDataAttr(key, value))
+		case "host":
+			port, _ := data["port"].(uint16)
+			config := &pgx.ConnConfig{Config: pgconn.Config{Host: stringValue(value), Port: port}}
+			kvs = append(kvs, r.semConvStability.serverAttributes(config)...)
+		case "port":
+			if _, ok := data["host"]; !ok {
+				group = append(group, logDataAttr(key, value))
+			}
+		case "database":
+			config := &pgx.ConnConfig{Config: pgconn.Config{Database: stringValue(value)}}
+			kvs = append(kvs, r.semConvStability.databaseAttributes(config)...)
+		default:
+			group = append(group, logDataAttr(key, value))
+		}
+	}
+
+	for _, kv := range kvs {
+		if key, ok := strings.CutPrefix(string(kv.Key), logDataGroup+"."); ok {
+			group = append(group, slog.Attr{Key: key, Value: slogValue(kv.Value)})
+			continue
+		}
+		attrs = append(attrs, slog.Attr{Key: string(kv.Key), Value: slogValue(kv.Value)})
+	}
+
+	sortAttrs(attrs)
+	sortAttrs(group)
+
+	if len(group) > 0 {
+		attrs = append(attrs, slog.Attr{Key: logDataGroup, Value: slog.GroupValue(group...)})
+	}
+
+	return attrs
+}
+
+// sortAttrs sorts attrs by key.
+func sortAttrs(attrs []slog.Attr) {
+	sort.Slice(attrs, func(i, j int) bool {
+		return attrs[i].Key < attrs[j].Key
+	})
+}
+
+// errorLogAttributes returns the attributes describing the err entry of the
+// data of pgx, as recorded on batch query events.
+func errorLogAttributes(value any) []attribute.KeyValue {
+	err, ok := value.(error)
+	if !ok {
+		return []attribute.KeyValue{ErrorKey.Bool(true), semconv.ExceptionMessage(stringValue(value))}
+	}
+
+	attrs := []attribute.KeyValue{ErrorKey.Bool(true), semconv.ExceptionMessage(err.Error())}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		attrs = append(attrs, SQLStateKey.String(pgErr.Code))
+	}
+
+	return attrs
+}
+
+// logDataAttr returns the attribute of an unknown entry of the data of pgx.
+func logDataAttr(key string, value any) slog.Attr {
+	if d, ok := value.(time.Duration); ok {
+		return slog.Float64(key+"_ms", milliseconds(d))
+	}
+	return slog.Any(key, value)
+}
+
+// stringValue returns value as a string, formatting it if it is not one.
+func stringValue(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return formatParam(value)
+}
+
+// slogValue converts an attribute value to a slog value.
+func slogValue(v attribute.Value) slog.Value {
+	switch v.Type() {
+	case attribute.BOOL:
+		return slog.BoolValue(v.AsBool())
+	case attribute.INT64:
+		return slog.Int64Value(v.AsInt64())
+	case attribute.FLOAT64:
+		return slog.Float64Value(v.AsFloat64())
+	case attribute.STRING:
+		return slog.StringValue(v.AsString())
+	default:
+		return slog.AnyValue(v.AsInterface())
+	}
+}
@@ -0,0 +1,161 @@
+package otelpgx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/tracelog"
+)
+
+func TestNewLogDataRenderer(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []LogDataOption
+		data map[string]any
+		want map[string]any
+	}{
+		{
+			name: "Query",
+			opts: []LogDataOption{WithLogSemConvStability(SemConvStabilityOld)},
+			data: map[string]any{
+				"sql":        "SELECT $1",
+				"args":       []any{"secret", 42},
+				"time":       1500 * time.Microsecond,
+				"commandTag": "SELECT 1",
+				"pid":        uint32(1234),
+			},
+			want: map[string]any{
+				"db.statement":         "SELECT $1",
+				"pgx.backend_pid":      int64(1234),
+				"pgx.command_tag":      "SELECT 1",
+				"pgx.duration_ms":      1.5,
+				"pgx.query.parameters": []string{"secret", "42"},
+			},
+		},
+		{
+			name: "Redacted and truncated args",
+			opts: []LogDataOption{
+				WithLogQueryParameterFormatter(NewParamFormatter(3)),
+				WithLogQueryParameterRedactor(RedactParams("$1")),
+			},
+			data: map[string]any{"args": []any{"secret", "abcdef"}},
+			want: map[string]any{"pgx.query.parameters": []string{redactedParam, "abc..."}},
+		},
+		{
+			name: "Args logged by tracelog",
+			data: map[string]any{"args": []any{"deadbeef", "abc (truncated 3 bytes)"}},
+			want: map[string]any{"pgx.query.parameters": []string{"deadbeef", "abc (truncated 3 bytes)"}},
+		},
+		{
+			name: "Without args",
+			opts: []LogDataOption{WithoutLogArgs()},
+			data: map[string]any{"args": []any{"secret"}, "time": time.Millisecond},
+			want: map[string]any{"pgx.duration_ms": 1.0},
+		},
+		{
+			name: "Error",
+			data: map[string]any{"err": &pgconn.PgError{Severity: "ERROR", Code: "23505", Message: "duplicate key"}},
+			want: map[string]any{
+				"exception.message": "ERROR: duplicate key (SQLSTATE 23505)",
+				"pgx.error":         true,
+				"pgx.sql_state":     "23505",
+			},
+		},
+		{
+			name: "Nil error",
+			data: map[string]any{"err": nil, "rowCount": int64(3)},
+			want: map[string]any{"pgx.rows_affected": int64(3)},
+		},
+		{
+			name: "Copy",
+			opts: []LogDataOption{WithLogSemConvStability(SemConvStabilityNew)},
+			data: map[string]any{
+				"tableName":   pgx.Identifier{"public", "users"},
+				"columnNames": []string{"id", "name"},
+			},
+			want: map[string]any{
+				"db.collection.name": `"public"."users"`,
+				"pgx.copy.columns":   []string{"id", "name"},
+			},
+		},
+		{
+			name: "Connect",
+			opts: []LogDataOption{WithLogSemConvStability(SemConvStabilityNew)},
+			data: map[string]any{"host": "localhost", "port": uint16(5432), "database": "app"},
+			want: map[string]any{
+				"db.namespace":   "app",
+				"server.address": "localhost",
+				"server.port":    int64(5432),
+			},
+		},
+		{
+			name: "Unknown keys",
+			data: map[string]any{"custom": "value", "elapsed": 2 * time.Millisecond},
+			want: map[string]any{"pgx.custom": "value", "pgx.elapsed_ms": 2.0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attrs := NewLogDataRenderer(tt.opts...)(tt.data)
+
+			var group []slog.Attr
+			if n := len(attrs); n > 0 && attrs[n-1].Key == logDataGroup {
+				group = attrs[n-1].Value.Group()
+				attrs = attrs[:n-1]
+			}
+
+			got := make(map[string]any, len(attrs)+len(group))
+			for prefix, attrs := range map[string][]slog.Attr{"": attrs, logDataGroup + ".": group} {
+				for i, attr := range attrs {
+					if i > 0 && attrs[i-1].Key >= attr.Key {
+						t.Errorf("attributes not sorted: %q before %q", attrs[i-1].Key, attr.Key)
+					}
+					if attr.Value.Kind() == slog.KindGroup {
+						t.Errorf("unexpected group %q", attr.Key)
+					}
+					got[prefix+attr.Key] = attr.Value.Any()
+				}
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogger_LogDataRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	l := newLogger(
+		WithLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+			Level: slog.LevelInfo,
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if a.Key == slog.TimeKey && len(groups) == 0 {
+					return slog.Attr{}
+				}
+				return a
+			},
+		}))),
+		WithLogDataRenderer(NewLogDataRenderer(WithLogSemConvStability(SemConvStabilityOld))),
+	)
+
+	l.Log(context.Background(), tracelog.LogLevelInfo, "Query", map[string]any{
+		"sql":  "SELECT 1",
+		"time": 2 * time.Millisecond,
+		"err":  errors.New("boom"),
+	})
+
+	want := `level=INFO msg=Query db.statement="SELECT 1" exception.message=boom pgx.duration_ms=2 pgx.error=true`
+	if got := strings.TrimSpace(buf.String()); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
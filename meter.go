@@ -7,10 +7,9 @@ import (
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/piusalfred/otelpgx/internal"
-	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
-	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
-	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
 )
 
 const (
@@ -28,6 +27,9 @@ const (
 	pgxpoolTotalConns              = "pgxpool_total_conns"
 )
 
+// PoolNameKey represents the name given to a pool with WithPoolName.
+const PoolNameKey = attribute.Key("pgx.pool.name")
+
 // MeterOption allows for managing otelsql configuration using functional options.
 type MeterOption interface {
 	applyMeterOptions(o *Meter)
@@ -42,6 +44,13 @@ type Meter struct {
 
 	// observeOptions will be set to each metrics as default.
 	observeOptions []metric.ObserveOption
+
+	// poolConfigAttributes adds the database name and server address of the
+	// pool's config to each metric.
+	poolConfigAttributes bool
+
+	// semConvStability selects the semantic conventions of the attributes.
+	semConvStability SemConvStability
 }
 
 type MeterOptionFunc func(o *Meter)
@@ -50,13 +59,16 @@ func (f MeterOptionFunc) applyMeterOptions(o *Meter) {
 	f(o)
 }
 
-// WithMeterProvider sets meter provider.
+// WithMeterProvider sets meter provider. If none is specified, the global
+// provider is used.
 func WithMeterProvider(p metric.MeterProvider) MeterOption {
 	return struct {
 		MeterOptionFunc
 	}{
 		MeterOptionFunc: func(o *Meter) {
-			o.provider = p
+			if p != nil {
+				o.provider = p
+			}
 		},
 	}
 }
@@ -68,49 +80,67 @@ func WithMinimumReadDBStatsInterval(interval time.Duration) MeterOption {
 	})
 }
 
+// WithPoolName adds the pgx.pool.name attribute to each metric, so that the
+// stats of several pools in one process can be told apart.
+func WithPoolName(name string) MeterOption {
+	return MeterOptionFunc(func(o *Meter) {
+		o.observeOptions = append(o.observeOptions, metric.WithAttributes(PoolNameKey.String(name)))
+	})
+}
+
+// WithPoolConfigAttributes adds the database name, server address and server
+// port taken from the pool's config to each metric, as db.name, net.peer.name
+// and net.peer.port, or db.namespace, server.address and server.port,
+// depending on the semantic conventions used.
+func WithPoolConfigAttributes() MeterOption {
+	return MeterOptionFunc(func(o *Meter) {
+		o.poolConfigAttributes = true
+	})
+}
+
+// WithMeterSemConvStability specifies the semantic conventions used by the
+// metric attributes, such as db.system or db.system.name. If none is
+// specified, it is read from the OTEL_SEMCONV_STABILITY_OPT_IN environment
+// variable, as for the Tracer.
+func WithMeterSemConvStability(stability SemConvStability) MeterOption {
+	return MeterOptionFunc(func(o *Meter) {
+		o.semConvStability = stability
+	})
+}
+
 const (
 	UnitDimensionless = "1"
 	UnitBytes         = "By"
 	UnitMilliseconds  = "ms"
 )
 
-func newMeterProvider() (*sdkmetric.MeterProvider, error) {
-	metricExporter, err := stdoutmetric.New()
-	if err != nil {
-		return nil, err
-	}
-
-	meterProvider := sdkmetric.NewMeterProvider(
-		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter,
-			// Default is 1m. Set to 3s for demonstrative purposes.
-			sdkmetric.WithInterval(3*time.Second))),
-	)
-	return meterProvider, nil
-}
-
 // defaultMinimumReadDBStatsInterval is the default minimum interval between calls to db.Stats().
 const defaultMinimumReadDBStatsInterval = time.Second
 
 // RecordStats records database statistics for provided pgxpool.Pool at the provided interval.
-func RecordStats(db *pgxpool.Pool, opts ...MeterOption) error {
-	mp, err := newMeterProvider()
-	if err != nil {
-		return err
-	}
+// The returned metric.Registration unregisters the stats callback, it should be
+// called when the pool is closed.
+func RecordStats(db *pgxpool.Pool, opts ...MeterOption) (metric.Registration, error) {
 	o := Meter{
-		provider:                   mp,
+		provider:                   otel.GetMeterProvider(),
 		minimumReadDBStatsInterval: defaultMinimumReadDBStatsInterval,
-		observeOptions: []metric.ObserveOption{
-			metric.WithAttributes(
-				semconv.DBSystemPostgreSQL,
-			),
-		},
+		semConvStability:           semConvStabilityFromEnv(),
 	}
 
 	for _, opt := range opts {
 		opt.applyMeterOptions(&o)
 	}
 
+	attrs := o.semConvStability.systemAttributes()
+
+	if o.poolConfigAttributes {
+		cc := db.Config().ConnConfig
+		attrs = append(attrs, o.semConvStability.databaseAttributes(cc)...)
+		attrs = append(attrs, o.semConvStability.serverAttributes(cc)...)
+	}
+
+	o.observeOptions = append([]metric.ObserveOption{metric.WithAttributes(attrs...)}, o.observeOptions...)
+
 	meter := o.provider.Meter(internal.MeterName)
 
 	return recordStats(meter, db, o.minimumReadDBStatsInterval, o.observeOptions...)
@@ -121,7 +151,7 @@ func recordStats(
 	db *pgxpool.Pool,
 	minimumReadDBStatsInterval time.Duration,
 	attrs ...metric.ObserveOption,
-) error {
+) (metric.Registration, error) {
 	var (
 		err error
 
@@ -153,7 +183,7 @@ func recordStats(
 		metric.WithUnit(UnitDimensionless),
 		metric.WithDescription("Cumulative count of successful acquires from the pool."),
 	); err != nil {
-		return err
+		return nil, err
 	}
 
 	if acquireDuration, err = meter.Float64ObservableCounter(
@@ -161,7 +191,7 @@ func recordStats(
 		metric.WithUnit(UnitDimensionless),
 		metric.WithDescription("Total duration of all successful acquires from the pool in nanoseconds."),
 	); err != nil {
-		return err
+		return nil, err
 	}
 
 	if acquiredConns, err = meter.Int64ObservableUpDownCounter(
@@ -169,7 +199,7 @@ func recordStats(
 		metric.WithUnit(UnitDimensionless),
 		metric.WithDescription("Number of currently acquired connections in the pool."),
 	); err != nil {
-		return err
+		return nil, err
 	}
 
 	if cancelledAcquires, err = meter.Int64ObservableCounter(
@@ -177,7 +207,7 @@ func recordStats(
 		metric.WithUnit(UnitDimensionless),
 		metric.WithDescription("Cumulative count of acquires from the pool that were canceled by a context."),
 	); err != nil {
-		return err
+		return nil, err
 	}
 
 	if constructingConns, err = meter.Int64ObservableUpDownCounter(
@@ -185,7 +215,7 @@ func recordStats(
 		metric.WithUnit(UnitMilliseconds),
 		metric.WithDescription("Number of conns with construction in progress in the pool."),
 	); err != nil {
-		return err
+		return nil, err
 	}
 
 	if emptyAcquires, err = meter.Int64ObservableCounter(
@@ -193,7 +223,7 @@ func recordStats(
 		metric.WithUnit(UnitDimensionless),
 		metric.WithDescription("Cumulative count of successful acquires from the pool that waited for a resource to be released or constructed because the pool was empty."),
 	); err != nil {
-		return err
+		return nil, err
 	}
 
 	if idleConns, err = meter.Int64ObservableUpDownCounter(
@@ -201,7 +231,7 @@ func recordStats(
 		metric.WithUnit(UnitDimensionless),
 		metric.WithDescription("Number of currently idle conns in the pool."),
 	); err != nil {
-		return err
+		return nil, err
 	}
 
 	if maxConns, err = meter.Int64ObservableGauge(
@@ -209,7 +239,7 @@ func recordStats(
 		metric.WithUnit(UnitDimensionless),
 		metric.WithDescription("Maximum size of the pool."),
 	); err != nil {
-		return err
+		return nil, err
 	}
 
 	if maxIdleDestroyCount, err = meter.Int64ObservableCounter(
@@ -217,7 +247,7 @@ func recordStats(
 		metric.WithUnit(UnitDimensionless),
 		metric.WithDescription("Cumulative count of connections destroyed because they exceeded MaxConnIdleTime."),
 	); err != nil {
-		return err
+		return nil, err
 	}
 
 	if maxLifetimeDestroyCountifetimeClosed, err = meter.Int64ObservableCounter(
@@ -225,7 +255,7 @@ func recordStats(
 		metric.WithUnit(UnitDimensionless),
 		metric.WithDescription("Cumulative count of connections destroyed because they exceeded MaxConnLifetime."),
 	); err != nil {
-		return err
+		return nil, err
 	}
 
 	if newConnsCount, err = meter.Int64ObservableCounter(
@@ -233,7 +263,7 @@ func recordStats(
 		metric.WithUnit(UnitDimensionless),
 		metric.WithDescription("Cumulative count of new connections opened."),
 	); err != nil {
-		return err
+		return nil, err
 	}
 
 	if totalConns, err = meter.Int64ObservableUpDownCounter(
@@ -241,10 +271,10 @@ func recordStats(
 		metric.WithUnit(UnitDimensionless),
 		metric.WithDescription("Total number of resources currently in the pool. The value is the sum of ConstructingConns, AcquiredConns, and IdleConns."),
 	); err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = meter.RegisterCallback(
+	return meter.RegisterCallback(
 		func(ctx context.Context, o metric.Observer) error {
 			lock.Lock()
 			defer lock.Unlock()
@@ -283,6 +313,4 @@ func recordStats(
 		newConnsCount,
 		totalConns,
 	)
-
-	return err
 }
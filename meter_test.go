@@ -0,0 +1,182 @@
+package otelpgx
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+)
+
+func TestRecordStats(t *testing.T) {
+	// The pool connects lazily, so no server is needed.
+	primary, err := pgxpool.New(context.Background(), "postgres://app@primary.example:5432/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer primary.Close()
+
+	replica, err := pgxpool.New(context.Background(), "postgres://app@replica.example:5433/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer replica.Close()
+
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	primaryReg, err := RecordStats(primary,
+		WithMeterProvider(mp),
+		WithPoolName("primary"),
+		WithPoolConfigAttributes(),
+		WithMeterSemConvStability(SemConvStabilityOld),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replicaReg, err := RecordStats(replica,
+		WithMeterProvider(mp),
+		WithPoolName("replica"),
+		WithPoolConfigAttributes(),
+		WithMeterSemConvStability(SemConvStabilityNew),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]attribute.Set{
+		"primary": attribute.NewSet(
+			semconv.DBSystemPostgreSQL,
+			PoolNameKey.String("primary"),
+			semconv.DBName("app"),
+			semconv.NetPeerName("primary.example"),
+			semconv.NetPeerPort(5432),
+		),
+		"replica": attribute.NewSet(
+			DBSystemNameKey.String("postgresql"),
+			PoolNameKey.String("replica"),
+			DBNamespaceKey.String("app"),
+			semconv.ServerAddress("replica.example"),
+			semconv.ServerPort(5433),
+		),
+	}
+
+	got := collectMaxConnsAttributes(t, reader)
+	if len(got) != len(want) {
+		t.Fatalf("got %d data points, want %d", len(got), len(want))
+	}
+	for _, set := range got {
+		name, _ := set.Value(PoolNameKey)
+		if w, ok := want[name.AsString()]; !ok || !w.Equals(&set) {
+			t.Errorf("unexpected attributes %v", set.ToSlice())
+		}
+	}
+
+	if err := replicaReg.Unregister(); err != nil {
+		t.Fatal(err)
+	}
+
+	got = collectMaxConnsAttributes(t, reader)
+	if len(got) != 1 {
+		t.Fatalf("got %d data points after unregister, want 1", len(got))
+	}
+
+	if err := primaryReg.Unregister(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecordStats_globalMeterProvider(t *testing.T) {
+	pool, err := pgxpool.New(context.Background(), "postgres://app@primary.example:5432/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	reader := sdkmetric.NewManualReader()
+	// The global provider cannot be reset, and no other test relies on it.
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+
+	t.Setenv(semConvStabilityOptInEnv, "")
+
+	reg, err := RecordStats(pool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reg.Unregister()
+
+	got := collectMaxConnsAttributes(t, reader)
+	if len(got) != 1 {
+		t.Fatalf("got %d data points, want 1", len(got))
+	}
+	if want := attribute.NewSet(semconv.DBSystemPostgreSQL); !want.Equals(&got[0]) {
+		t.Errorf("unexpected attributes %v", got[0].ToSlice())
+	}
+}
+
+func TestRecordStats_error(t *testing.T) {
+	pool, err := pgxpool.New(context.Background(), "postgres://app@primary.example:5432/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	reg, err := RecordStats(pool, WithMeterProvider(errMeterProvider{}))
+	if !errors.Is(err, errInstrument) {
+		t.Errorf("RecordStats() error = %v, want %v", err, errInstrument)
+	}
+	if reg != nil {
+		t.Errorf("RecordStats() registration = %v, want nil", reg)
+	}
+}
+
+var errInstrument = errors.New("instrument creation failed")
+
+// errMeterProvider provides meters failing to create observable counters.
+type errMeterProvider struct {
+	noop.MeterProvider
+}
+
+func (errMeterProvider) Meter(string, ...metric.MeterOption) metric.Meter {
+	return errMeter{}
+}
+
+type errMeter struct {
+	noop.Meter
+}
+
+func (errMeter) Int64ObservableCounter(string, ...metric.Int64ObservableCounterOption) (metric.Int64ObservableCounter, error) {
+	return nil, errInstrument
+}
+
+// collectMaxConnsAttributes returns the attribute sets of the
+// pgxpool_max_conns data points.
+func collectMaxConnsAttributes(t *testing.T, reader sdkmetric.Reader) []attribute.Set {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+
+	var sets []attribute.Set
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != pgxpoolMaxConns {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Gauge[int64]).DataPoints {
+				sets = append(sets, dp.Attributes)
+			}
+		}
+	}
+	return sets
+}
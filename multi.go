@@ -0,0 +1,221 @@
+package otelpgx
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	_ pgx.QueryTracer       = (*MultiTracer)(nil)
+	_ pgx.BatchTracer       = (*MultiTracer)(nil)
+	_ pgx.CopyFromTracer    = (*MultiTracer)(nil)
+	_ pgx.PrepareTracer     = (*MultiTracer)(nil)
+	_ pgx.ConnectTracer     = (*MultiTracer)(nil)
+	_ pgxpool.AcquireTracer = (*MultiTracer)(nil)
+	_ pgxpool.ReleaseTracer = (*MultiTracer)(nil)
+)
+
+// MultiTracer forwards the pgx and pgxpool tracer hooks to several tracers,
+// such as a Tracer and the tracelog.TraceLog returned by NewTraceLogger:
+//
+//	cfg.ConnConfig.Tracer = otelpgx.NewMultiTracer(otelpgx.NewTracer(), otelpgx.NewTraceLogger())
+//
+// Each hook is only forwarded to the tracers implementing it. Each tracer is
+// passed the context it returned from the matching Trace*Start hook, so the
+// span started by one tracer is not seen by the others. The rest of the call,
+// including query rewriters such as the one of Tracer.Querier, uses the
+// context returned by the first tracer.
+type MultiTracer struct {
+	tracers []pgx.QueryTracer
+}
+
+// NewMultiTracer returns a MultiTracer forwarding the hooks to tracers, in
+// order. Nil tracers are ignored.
+func NewMultiTracer(tracers ...pgx.QueryTracer) *MultiTracer {
+	m := &MultiTracer{}
+	for _, tracer := range tracers {
+		if tracer != nil {
+			m.tracers = append(m.tracers, tracer)
+		}
+	}
+	return m
+}
+
+type multiTracerKey struct{}
+
+// multiTracerState is stored in the context returned by the Trace*Start
+// hooks, holding the context returned by each tracer.
+type multiTracerState struct {
+	tracer *MultiTracer
+	ctxs   []context.Context
+}
+
+// contexts returns the context each tracer is passed for an operation run
+// with ctx. Operations run within another one, such as the prepare of a
+// query, are passed the contexts returned for the enclosing operation.
+func (m *MultiTracer) contexts(ctx context.Context) []context.Context {
+	ctxs := make([]context.Context, len(m.tracers))
+
+	if state, ok := ctx.Value(multiTracerKey{}).(*multiTracerState); ok && state.tracer == m {
+		copy(ctxs, state.ctxs)
+		return ctxs
+	}
+
+	for i := range ctxs {
+		ctxs[i] = ctx
+	}
+	return ctxs
+}
+
+// withContexts returns the context returned by the first tracer, or ctx if
+// there is none, carrying the contexts returned by all of them.
+func (m *MultiTracer) withContexts(ctx context.Context, ctxs []context.Context) context.Context {
+	if len(ctxs) > 0 {
+		ctx = ctxs[0]
+	}
+	return context.WithValue(ctx, multiTracerKey{}, &multiTracerState{tracer: m, ctxs: ctxs})
+}
+
+// TraceQueryStart is called at the beginning of Query, QueryRow, and Exec calls.
+func (m *MultiTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	ctxs := m.contexts(ctx)
+	for i, tracer := range m.tracers {
+		ctxs[i] = tracer.TraceQueryStart(ctxs[i], conn, data)
+	}
+	return m.withContexts(ctx, ctxs)
+}
+
+// TraceQueryEnd is called at the end of Query, QueryRow, and Exec calls.
+func (m *MultiTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	ctxs := m.contexts(ctx)
+	for i, tracer := range m.tracers {
+		tracer.TraceQueryEnd(ctxs[i], conn, data)
+	}
+}
+
+// TraceBatchStart is called at the beginning of SendBatch calls.
+func (m *MultiTracer) TraceBatchStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	ctxs := m.contexts(ctx)
+	for i, tracer := range m.tracers {
+		if t, ok := tracer.(pgx.BatchTracer); ok {
+			ctxs[i] = t.TraceBatchStart(ctxs[i], conn, data)
+		}
+	}
+	return m.withContexts(ctx, ctxs)
+}
+
+// TraceBatchQuery is called after each query in a batch.
+func (m *MultiTracer) TraceBatchQuery(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchQueryData) {
+	ctxs := m.contexts(ctx)
+	for i, tracer := range m.tracers {
+		if t, ok := tracer.(pgx.BatchTracer); ok {
+			t.TraceBatchQuery(ctxs[i], conn, data)
+		}
+	}
+}
+
+// TraceBatchEnd is called at the end of SendBatch calls.
+func (m *MultiTracer) TraceBatchEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchEndData) {
+	ctxs := m.contexts(ctx)
+	for i, tracer := range m.tracers {
+		if t, ok := tracer.(pgx.BatchTracer); ok {
+			t.TraceBatchEnd(ctxs[i], conn, data)
+		}
+	}
+}
+
+// TraceCopyFromStart is called at the beginning of CopyFrom calls.
+func (m *MultiTracer) TraceCopyFromStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	ctxs := m.contexts(ctx)
+	for i, tracer := range m.tracers {
+		if t, ok := tracer.(pgx.CopyFromTracer); ok {
+			ctxs[i] = t.TraceCopyFromStart(ctxs[i], conn, data)
+		}
+	}
+	return m.withContexts(ctx, ctxs)
+}
+
+// TraceCopyFromEnd is called at the end of CopyFrom calls.
+func (m *MultiTracer) TraceCopyFromEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	ctxs := m.contexts(ctx)
+	for i, tracer := range m.tracers {
+		if t, ok := tracer.(pgx.CopyFromTracer); ok {
+			t.TraceCopyFromEnd(ctxs[i], conn, data)
+		}
+	}
+}
+
+// TracePrepareStart is called at the beginning of Prepare calls.
+func (m *MultiTracer) TracePrepareStart(ctx context.Context, conn *pgx.Conn, data pgx.TracePrepareStartData) context.Context {
+	ctxs := m.contexts(ctx)
+	for i, tracer := range m.tracers {
+		if t, ok := tracer.(pgx.PrepareTracer); ok {
+			ctxs[i] = t.TracePrepareStart(ctxs[i], conn, data)
+		}
+	}
+	return m.withContexts(ctx, ctxs)
+}
+
+// TracePrepareEnd is called at the end of Prepare calls.
+func (m *MultiTracer) TracePrepareEnd(ctx context.Context, conn *pgx.Conn, data pgx.TracePrepareEndData) {
+	ctxs := m.contexts(ctx)
+	for i, tracer := range m.tracers {
+		if t, ok := tracer.(pgx.PrepareTracer); ok {
+			t.TracePrepareEnd(ctxs[i], conn, data)
+		}
+	}
+}
+
+// TraceConnectStart is called at the beginning of Connect and ConnectConfig
+// calls.
+func (m *MultiTracer) TraceConnectStart(ctx context.Context, data pgx.TraceConnectStartData) context.Context {
+	ctxs := m.contexts(ctx)
+	for i, tracer := range m.tracers {
+		if t, ok := tracer.(pgx.ConnectTracer); ok {
+			ctxs[i] = t.TraceConnectStart(ctxs[i], data)
+		}
+	}
+	return m.withContexts(ctx, ctxs)
+}
+
+// TraceConnectEnd is called at the end of Connect and ConnectConfig calls.
+func (m *MultiTracer) TraceConnectEnd(ctx context.Context, data pgx.TraceConnectEndData) {
+	ctxs := m.contexts(ctx)
+	for i, tracer := range m.tracers {
+		if t, ok := tracer.(pgx.ConnectTracer); ok {
+			t.TraceConnectEnd(ctxs[i], data)
+		}
+	}
+}
+
+// TraceAcquireStart is called at the beginning of Acquire.
+func (m *MultiTracer) TraceAcquireStart(ctx context.Context, pool *pgxpool.Pool, data pgxpool.TraceAcquireStartData) context.Context {
+	ctxs := m.contexts(ctx)
+	for i, tracer := range m.tracers {
+		if t, ok := tracer.(pgxpool.AcquireTracer); ok {
+			ctxs[i] = t.TraceAcquireStart(ctxs[i], pool, data)
+		}
+	}
+	return m.withContexts(ctx, ctxs)
+}
+
+// TraceAcquireEnd is called when a connection has been acquired.
+func (m *MultiTracer) TraceAcquireEnd(ctx context.Context, pool *pgxpool.Pool, data pgxpool.TraceAcquireEndData) {
+	ctxs := m.contexts(ctx)
+	for i, tracer := range m.tracers {
+		if t, ok := tracer.(pgxpool.AcquireTracer); ok {
+			t.TraceAcquireEnd(ctxs[i], pool, data)
+		}
+	}
+}
+
+// TraceRelease is called at the beginning of Release.
+func (m *MultiTracer) TraceRelease(pool *pgxpool.Pool, data pgxpool.TraceReleaseData) {
+	for _, tracer := range m.tracers {
+		if t, ok := tracer.(pgxpool.ReleaseTracer); ok {
+			t.TraceRelease(pool, data)
+		}
+	}
+}
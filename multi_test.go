@@ -0,0 +1,92 @@
+package otelpgx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type queryOnlyTracerKey struct{}
+
+// queryOnlyTracer implements pgx.QueryTracer only, recording the query it
+// started in the context.
+type queryOnlyTracer struct {
+	ended []string
+	spans []trace.SpanContext
+}
+
+func (q *queryOnlyTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryOnlyTracerKey{}, data.SQL)
+}
+
+func (q *queryOnlyTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryEndData) {
+	sql, _ := ctx.Value(queryOnlyTracerKey{}).(string)
+	q.ended = append(q.ended, sql)
+	q.spans = append(q.spans, trace.SpanContextFromContext(ctx))
+}
+
+func TestMultiTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	queryOnly := &queryOnlyTracer{}
+
+	m := NewMultiTracer(NewTracer(WithTracerProvider(tp)), nil, queryOnly, NewTracer(WithTracerProvider(tp)))
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+
+	queryCtx := m.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "SELECT $1"})
+	prepareCtx := m.TracePrepareStart(queryCtx, nil, pgx.TracePrepareStartData{SQL: "SELECT $1"})
+	m.TracePrepareEnd(prepareCtx, nil, pgx.TracePrepareEndData{})
+	m.TraceQueryEnd(queryCtx, nil, pgx.TraceQueryEndData{})
+
+	batchCtx := m.TraceBatchStart(ctx, nil, pgx.TraceBatchStartData{Batch: &pgx.Batch{}})
+	m.TraceBatchEnd(batchCtx, nil, pgx.TraceBatchEndData{})
+
+	parent.End()
+
+	if len(queryOnly.ended) != 1 || queryOnly.ended[0] != "SELECT $1" {
+		t.Errorf("query only tracer ended %q, want one query with its own context", queryOnly.ended)
+	}
+	if queryOnly.spans[0].SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("query only tracer saw span %v, want the parent span", queryOnly.spans[0].SpanID())
+	}
+
+	if got := trace.SpanContextFromContext(queryCtx); got.SpanID() == parent.SpanContext().SpanID() {
+		t.Error("the returned context does not carry the query span of the first tracer")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 7 {
+		t.Fatalf("got %d ended spans, want 7", len(spans))
+	}
+
+	byID := make(map[trace.SpanID]sdktrace.ReadOnlySpan)
+	for _, span := range spans {
+		byID[span.SpanContext().SpanID()] = span
+	}
+
+	for _, span := range spans {
+		parentName := "parent"
+		switch span.Name() {
+		case "parent":
+			continue
+		case "prepare SELECT $1":
+			parentName = "query SELECT $1"
+		}
+
+		if got := byID[span.Parent().SpanID()]; got == nil || got.Name() != parentName {
+			t.Errorf("parent of %q is not %q", span.Name(), parentName)
+		}
+	}
+}
+
+func TestMultiTracer_empty(t *testing.T) {
+	m := NewMultiTracer()
+
+	ctx := m.TraceConnectStart(context.Background(), pgx.TraceConnectStartData{})
+	m.TraceConnectEnd(ctx, pgx.TraceConnectEndData{})
+}
@@ -0,0 +1,183 @@
+package otelpgx
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/tracelog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// NoticeSeverityKey represents the severity of a notice, such as NOTICE
+	// or WARNING.
+	NoticeSeverityKey = attribute.Key("pgx.notice.severity")
+	// NoticeCodeKey represents the SQLSTATE code of a notice.
+	NoticeCodeKey = attribute.Key("pgx.notice.code")
+	// NoticeMessageKey represents the message of a notice.
+	NoticeMessageKey = attribute.Key("pgx.notice.message")
+)
+
+// noticeEvent is the name of the span event recording a notice.
+const noticeEvent = "notice"
+
+// OnNotice returns a pgconn.NoticeHandler recording the notices and warnings
+// sent by the server, for example by RAISE NOTICE, as events on the span of
+// the query, batch, copy or prepare they were raised by. Install it on the
+// connection config:
+//
+//	cfg.ConnConfig.OnNotice = tracer.OnNotice()
+//
+// Notices are also logged if logger options are given, at the Warn level for
+// warnings and at the Info or Debug level for the others.
+func (t *Tracer) OnNotice(opts ...LoggerOption) pgconn.NoticeHandler {
+	t.trackNotices.Store(true)
+
+	var logger *Logger
+	if len(opts) > 0 {
+		l := newLogger(opts...)
+		logger = &l
+	}
+
+	return func(conn *pgconn.PgConn, notice *pgconn.Notice) {
+		span := activeNoticeSpan(conn)
+		span.AddEvent(noticeEvent, trace.WithAttributes(
+			NoticeSeverityKey.String(notice.Severity),
+			NoticeCodeKey.String(notice.Code),
+			NoticeMessageKey.String(notice.Message),
+		))
+
+		if logger == nil {
+			return
+		}
+
+		level := noticeLogLevel(notice.Severity)
+		if logger.converter.ToTraceLogLevel(logger.level) < level {
+			return
+		}
+
+		data := map[string]any{
+			"severity": notice.Severity,
+			"code":     notice.Code,
+			"pid":      conn.PID(),
+		}
+		if notice.Detail != "" {
+			data["detail"] = notice.Detail
+		}
+		if notice.Where != "" {
+			data["where"] = notice.Where
+		}
+
+		logger.Log(trace.ContextWithSpan(context.Background(), span), level, notice.Message, data)
+	}
+}
+
+// noticeLogLevel returns the level notices of the given severity are logged
+// at.
+func noticeLogLevel(severity string) tracelog.LogLevel {
+	switch {
+	case severity == "WARNING":
+		return tracelog.LogLevelWarn
+	case strings.HasPrefix(severity, "DEBUG"):
+		return tracelog.LogLevelDebug
+	default:
+		return tracelog.LogLevelInfo
+	}
+}
+
+// noticeSpansKey is the pgconn.PgConn custom data key under which the spans
+// of the operations in progress on the connection are tracked. Notices are
+// received without a context, while an operation reads the responses of the
+// server.
+const noticeSpansKey = "github.com/piusalfred/otelpgx.noticeSpans"
+
+// noticeSpans is the stack of the spans of the operations in progress on a
+// connection. An operation may run another one, such as the prepare of a
+// query.
+type noticeSpans struct {
+	spans []trace.Span
+}
+
+// pushNoticeSpan makes the span in ctx the span notices received on conn are
+// recorded on, until popNoticeSpan is called.
+func (t *Tracer) pushNoticeSpan(ctx context.Context, conn *pgx.Conn) {
+	if conn != nil {
+		t.pushPgConnNoticeSpan(ctx, conn.PgConn())
+	}
+}
+
+// popNoticeSpan stops recording the notices received on conn on the span in
+// ctx.
+func (t *Tracer) popNoticeSpan(ctx context.Context, conn *pgx.Conn) {
+	if conn != nil {
+		t.popPgConnNoticeSpan(ctx, conn.PgConn())
+	}
+}
+
+// pushPgConnNoticeSpan is pushNoticeSpan for a pgconn.PgConn.
+func (t *Tracer) pushPgConnNoticeSpan(ctx context.Context, conn *pgconn.PgConn) {
+	span := trace.SpanFromContext(ctx)
+	if !t.trackNotices.Load() || !span.IsRecording() {
+		return
+	}
+
+	data := conn.CustomData()
+	if data == nil {
+		return
+	}
+
+	stack, ok := data[noticeSpansKey].(*noticeSpans)
+	if !ok {
+		stack = &noticeSpans{}
+		data[noticeSpansKey] = stack
+	}
+
+	stack.push(span)
+}
+
+// popPgConnNoticeSpan is popNoticeSpan for a pgconn.PgConn.
+func (t *Tracer) popPgConnNoticeSpan(ctx context.Context, conn *pgconn.PgConn) {
+	span := trace.SpanFromContext(ctx)
+	if !t.trackNotices.Load() || !span.IsRecording() {
+		return
+	}
+
+	if stack, ok := conn.CustomData()[noticeSpansKey].(*noticeSpans); ok {
+		stack.pop(span)
+	}
+}
+
+// activeNoticeSpan returns the span of the innermost operation in progress on
+// conn, or a non-recording span if there is none.
+func activeNoticeSpan(conn *pgconn.PgConn) trace.Span {
+	stack, _ := conn.CustomData()[noticeSpansKey].(*noticeSpans)
+	return stack.top()
+}
+
+func (s *noticeSpans) push(span trace.Span) {
+	s.spans = append(s.spans, span)
+}
+
+// pop removes span from the stack. Spans are compared by ID, as not all
+// trace.Span implementations are comparable.
+func (s *noticeSpans) pop(span trace.Span) {
+	id := span.SpanContext().SpanID()
+	for i := len(s.spans) - 1; i >= 0; i-- {
+		if s.spans[i].SpanContext().SpanID() == id {
+			s.spans = append(s.spans[:i], s.spans[i+1:]...)
+			return
+		}
+	}
+}
+
+// top returns the span on top of the stack, or a non-recording span if the
+// stack is nil or empty.
+func (s *noticeSpans) top() trace.Span {
+	if s == nil || len(s.spans) == 0 {
+		return trace.SpanFromContext(context.Background())
+	}
+	return s.spans[len(s.spans)-1]
+}
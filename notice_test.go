@@ -0,0 +1,88 @@
+package otelpgx
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/tracelog"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestNoticeSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	ctx, query := tp.Tracer("test").Start(context.Background(), "query")
+	_, prepare := tp.Tracer("test").Start(ctx, "prepare")
+
+	var stack *noticeSpans
+	if stack.top().IsRecording() {
+		t.Fatal("top() of a nil stack is recording")
+	}
+
+	stack = &noticeSpans{}
+	stack.push(query)
+	stack.push(prepare)
+	if got := stack.top(); got != prepare {
+		t.Errorf("top() = %v, want the prepare span", got)
+	}
+
+	stack.pop(prepare)
+	if got := stack.top(); got != query {
+		t.Errorf("top() = %v, want the query span", got)
+	}
+
+	stack.pop(query)
+	if len(stack.spans) != 0 {
+		t.Errorf("got %d spans after popping all of them", len(stack.spans))
+	}
+}
+
+func TestTracer_OnNotice(t *testing.T) {
+	tests := []struct {
+		name     string
+		severity string
+		level    slog.Level
+		wantLog  bool
+	}{
+		{name: "Warning", severity: "WARNING", level: slog.LevelWarn, wantLog: true},
+		{name: "Notice", severity: "NOTICE", level: slog.LevelInfo, wantLog: true},
+		{name: "Notice below level", severity: "NOTICE", level: slog.LevelWarn, wantLog: false},
+		{name: "Debug", severity: "DEBUG1", level: slog.LevelInfo, wantLog: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: tt.level}))
+
+			onNotice := NewTracer().OnNotice(WithLogger(logger))
+			onNotice(&pgconn.PgConn{}, &pgconn.Notice{Severity: tt.severity, Code: "01000", Message: "cache is stale"})
+
+			if got := strings.Contains(buf.String(), "cache is stale"); got != tt.wantLog {
+				t.Errorf("logged = %v, want %v: %s", got, tt.wantLog, buf.String())
+			}
+		})
+	}
+}
+
+func TestNoticeLogLevel(t *testing.T) {
+	tests := map[string]tracelog.LogLevel{
+		"WARNING": tracelog.LogLevelWarn,
+		"NOTICE":  tracelog.LogLevelInfo,
+		"INFO":    tracelog.LogLevelInfo,
+		"LOG":     tracelog.LogLevelInfo,
+		"DEBUG1":  tracelog.LogLevelDebug,
+	}
+
+	for severity, want := range tests {
+		if got := noticeLogLevel(severity); got != want {
+			t.Errorf("noticeLogLevel(%q) = %v, want %v", severity, got, want)
+		}
+	}
+}
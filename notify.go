@@ -0,0 +1,148 @@
+package otelpgx
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// notificationEnvelopePrefix starts the payloads wrapped by
+// WrapNotificationPayload, which tells them apart from plain payloads
+// without decoding them.
+const notificationEnvelopePrefix = `{"otelpgx":`
+
+// notificationEnvelope is the JSON payload of notifications carrying a trace
+// context.
+type notificationEnvelope struct {
+	Carrier propagation.MapCarrier `json:"otelpgx"`
+	Payload *string                `json:"payload"`
+}
+
+// WrapNotificationPayload returns payload wrapped in a JSON envelope carrying
+// the W3C trace context of ctx, such as
+// {"otelpgx":{"traceparent":"00-..."},"payload":"..."}, for NOTIFY or
+// pg_notify. Payload is returned as is if ctx carries no span. Note the
+// envelope counts towards the 8000 bytes limit of payloads.
+func WrapNotificationPayload(ctx context.Context, payload string) string {
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return payload
+	}
+
+	b, err := json.Marshal(notificationEnvelope{Carrier: carrier, Payload: &payload})
+	if err != nil {
+		return payload
+	}
+
+	return string(b)
+}
+
+// UnwrapNotificationPayload returns the payload wrapped by
+// WrapNotificationPayload and ctx with the trace context of the envelope as
+// remote span context. Plain payloads are returned as is, with ctx.
+func UnwrapNotificationPayload(ctx context.Context, payload string) (context.Context, string) {
+	if !strings.HasPrefix(payload, notificationEnvelopePrefix) {
+		return ctx, payload
+	}
+
+	var envelope notificationEnvelope
+
+	dec := json.NewDecoder(strings.NewReader(payload))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&envelope); err != nil || envelope.Payload == nil || dec.More() {
+		return ctx, payload
+	}
+
+	return propagation.TraceContext{}.Extract(ctx, envelope.Carrier), *envelope.Payload
+}
+
+// notifySQL sends a notification with pg_notify.
+const notifySQL = "SELECT pg_notify($1, $2)"
+
+// Notify sends a notification on channel with pg_notify, with payload
+// wrapped by WrapNotificationPayload in the context of a "publish {channel}"
+// producer span. Like the spans of statements, the producer span is only
+// started if ctx carries a recording span; otherwise, payload is wrapped in
+// the context of ctx.
+func (t *Tracer) Notify(ctx context.Context, db Querier, channel, payload string) error {
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		_, err := db.Exec(ctx, notifySQL, channel, WrapNotificationPayload(ctx, payload))
+		return err
+	}
+
+	ctx, span := t.tracer.Start(ctx, "publish "+channel,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(t.attrs...),
+		trace.WithAttributes(notificationAttributes(channel, payload)...),
+		trace.WithAttributes(semconv.MessagingOperationPublish),
+	)
+	defer span.End()
+
+	_, err := db.Exec(ctx, notifySQL, channel, WrapNotificationPayload(ctx, payload))
+	t.recordError(ctx, span, err)
+
+	return err
+}
+
+// NotificationWaiter waits for notifications. It is implemented by
+// *pgx.Conn and *pgconn.PgConn.
+type NotificationWaiter interface {
+	WaitForNotification(ctx context.Context) (*pgconn.Notification, error)
+}
+
+// WaitForNotification waits for a notification on conn and returns it with
+// its payload unwrapped by UnwrapNotificationPayload, and ctx with a
+// "receive {channel}" consumer span as the current span. The span is linked
+// to the span which sent the notification, if the payload carries its trace
+// context, and must be ended by the caller once the notification is
+// processed:
+//
+//	ctx, n, err := tracer.WaitForNotification(ctx, conn)
+//	if err != nil {
+//		return err
+//	}
+//	defer trace.SpanFromContext(ctx).End()
+//
+// No span is started if waiting fails.
+func (t *Tracer) WaitForNotification(ctx context.Context, conn NotificationWaiter) (context.Context, *pgconn.Notification, error) {
+	n, err := conn.WaitForNotification(ctx)
+	if err != nil {
+		return ctx, nil, err
+	}
+
+	producerCtx, payload := UnwrapNotificationPayload(context.Background(), n.Payload)
+
+	opts := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(t.attrs...),
+		trace.WithAttributes(notificationAttributes(n.Channel, payload)...),
+		trace.WithAttributes(semconv.MessagingOperationReceive),
+	}
+
+	if sc := trace.SpanContextFromContext(producerCtx); sc.IsValid() {
+		opts = append(opts, trace.WithLinks(trace.Link{SpanContext: sc}))
+	}
+
+	ctx, _ = t.tracer.Start(ctx, "receive "+n.Channel, opts...)
+
+	unwrapped := *n
+	unwrapped.Payload = payload
+
+	return ctx, &unwrapped, nil
+}
+
+// notificationAttributes returns the messaging attributes of a notification.
+func notificationAttributes(channel, payload string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		semconv.MessagingSystemKey.String("postgresql"),
+		semconv.MessagingDestinationName(channel),
+		semconv.MessagingMessageBodySize(len(payload)),
+	}
+}
@@ -0,0 +1,162 @@
+package otelpgx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// fakeNotifier sends notifications through Exec and returns them from
+// WaitForNotification.
+type fakeNotifier struct {
+	Querier
+
+	notifications []*pgconn.Notification
+}
+
+func (n *fakeNotifier) Exec(_ context.Context, _ string, args ...any) (pgconn.CommandTag, error) {
+	n.notifications = append(n.notifications, &pgconn.Notification{
+		PID:     42,
+		Channel: args[0].(string),
+		Payload: args[1].(string),
+	})
+	return pgconn.CommandTag{}, nil
+}
+
+func (n *fakeNotifier) WaitForNotification(context.Context) (*pgconn.Notification, error) {
+	notification := n.notifications[0]
+	n.notifications = n.notifications[1:]
+	return notification, nil
+}
+
+func TestUnwrapNotificationPayload(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+	}{
+		{name: "Empty", payload: ""},
+		{name: "Text", payload: "job:42"},
+		{name: "JSON", payload: `{"id":42}`},
+		{name: "Prefixed JSON", payload: `{"otelpgx":{"traceparent":"x"},"id":42}`},
+		{name: "Truncated", payload: `{"otelpgx":{`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, payload := UnwrapNotificationPayload(context.Background(), tt.payload)
+			if payload != tt.payload {
+				t.Errorf("payload = %q, want %q", payload, tt.payload)
+			}
+			if trace.SpanContextFromContext(ctx).IsValid() {
+				t.Error("got a span context from a plain payload")
+			}
+		})
+	}
+}
+
+func TestWrapNotificationPayload(t *testing.T) {
+	tp := sdktrace.NewTracerProvider()
+	ctx, span := tp.Tracer("test").Start(context.Background(), "producer")
+	defer span.End()
+
+	if got := WrapNotificationPayload(context.Background(), "job:42"); got != "job:42" {
+		t.Errorf("WrapNotificationPayload() without span = %q, want the payload as is", got)
+	}
+
+	wrapped := WrapNotificationPayload(ctx, `<"job":42>`)
+
+	remote, payload := UnwrapNotificationPayload(context.Background(), wrapped)
+	if payload != `<"job":42>` {
+		t.Errorf("payload = %q, want %q", payload, `<"job":42>`)
+	}
+	if got := trace.SpanContextFromContext(remote); got.SpanID() != span.SpanContext().SpanID() {
+		t.Errorf("span ID = %v, want %v", got.SpanID(), span.SpanContext().SpanID())
+	}
+}
+
+func TestTracer_NotifyAndWaitForNotification(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tr := NewTracer(WithTracerProvider(tp))
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+
+	conn := &fakeNotifier{}
+	if err := tr.Notify(ctx, conn, "jobs", "job:42"); err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+
+	// A notification sent by a client which does not wrap payloads.
+	conn.notifications = append(conn.notifications, &pgconn.Notification{Channel: "jobs", Payload: "job:43"})
+
+	for _, want := range []string{"job:42", "job:43"} {
+		ctx, n, err := tr.WaitForNotification(context.Background(), conn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		trace.SpanFromContext(ctx).End()
+
+		if n.Payload != want {
+			t.Errorf("payload = %q, want %q", n.Payload, want)
+		}
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 4 {
+		t.Fatalf("got %d ended spans, want 4", len(spans))
+	}
+
+	producer, consumer, plain := spans[0], spans[2], spans[3]
+
+	if producer.Name() != "publish jobs" || producer.SpanKind() != trace.SpanKindProducer {
+		t.Errorf("producer span = %q %v", producer.Name(), producer.SpanKind())
+	}
+	if producer.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Error("producer span is not a child of the caller span")
+	}
+	if consumer.Name() != "receive jobs" || consumer.SpanKind() != trace.SpanKindConsumer {
+		t.Errorf("consumer span = %q %v", consumer.Name(), consumer.SpanKind())
+	}
+
+	if links := consumer.Links(); len(links) != 1 || links[0].SpanContext.SpanID() != producer.SpanContext().SpanID() {
+		t.Errorf("consumer links = %v, want the producer span", links)
+	}
+	if links := plain.Links(); len(links) != 0 {
+		t.Errorf("links of a plain notification = %v, want none", links)
+	}
+
+	attrs := attribute.NewSet(consumer.Attributes()...)
+	for _, want := range []attribute.KeyValue{
+		semconv.MessagingDestinationName("jobs"),
+		semconv.MessagingMessageBodySize(len("job:42")),
+	} {
+		if got, _ := attrs.Value(want.Key); got != want.Value {
+			t.Errorf("%v = %v, want %v", want.Key, got.Emit(), want.Value.Emit())
+		}
+	}
+}
+
+func TestTracer_NotifyWithoutSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tr := NewTracer(WithTracerProvider(tp))
+
+	conn := &fakeNotifier{}
+	if err := tr.Notify(context.Background(), conn, "jobs", "job:42"); err != nil {
+		t.Fatal(err)
+	}
+
+	if spans := recorder.Ended(); len(spans) != 0 {
+		t.Errorf("got %d ended spans, want none", len(spans))
+	}
+	if got := conn.notifications[0].Payload; got != "job:42" {
+		t.Errorf("payload = %q, want the payload as is", got)
+	}
+}
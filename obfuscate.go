@@ -0,0 +1,119 @@
+package otelpgx
+
+import (
+	"strings"
+
+	"github.com/piusalfred/otelpgx/internal/sqltoken"
+)
+
+// ObfuscateSQL replaces the string, numeric, bit string, hex, bytea and
+// dollar-quoted constants in stmt with ?, and collapses IN lists made only of
+// constants, signed or not, and parameters into IN (?). Comments, identifiers, parameters
+// and casts are kept as is.
+func ObfuscateSQL(stmt string) string {
+	tokens := sqltoken.Tokenize(stmt)
+
+	var b strings.Builder
+	b.Grow(len(stmt))
+
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+
+		if tok.IsLiteral() {
+			b.WriteByte('?')
+			continue
+		}
+
+		b.WriteString(tok.Text)
+
+		if tok.IsKeyword("IN") {
+			if n := inList(tokens[i+1:]); n > 0 {
+				b.WriteString(" (?)")
+				i += n
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// inList returns the number of tokens making up the parenthesized list of
+// constants and parameters at the start of tokens, including the whitespace
+// before it, or 0 if tokens do not start with such a list.
+func inList(tokens []sqltoken.Token) int {
+	i := 0
+	for i < len(tokens) && (tokens[i].Kind == sqltoken.Whitespace || tokens[i].Kind == sqltoken.Comment) {
+		i++
+	}
+	if i == len(tokens) || tokens[i].Text != "(" {
+		return 0
+	}
+
+	elems := 0
+	for i++; i < len(tokens); i++ {
+		switch tok := tokens[i]; {
+		case tok.Text == ")":
+			if elems == 0 {
+				return 0
+			}
+			return i + 1
+		case tok.IsLiteral() || tok.Kind == sqltoken.Param:
+			elems++
+		case isSign(tok) && isElementStart(tokens[:i]) && startsWithLiteral(tokens[i+1:]):
+			// The sign of a constant such as -3, counted with it.
+		case tok.Text == "," || tok.Kind == sqltoken.Whitespace || tok.Kind == sqltoken.Comment:
+		case tok.Text == "::":
+		case tok.Kind == sqltoken.Ident && isCast(tokens[:i]):
+			// The type of a cast such as $1::int.
+		default:
+			return 0
+		}
+	}
+
+	return 0
+}
+
+// isCast reports whether the last non-whitespace token is the :: operator.
+func isCast(tokens []sqltoken.Token) bool {
+	for i := len(tokens) - 1; i >= 0; i-- {
+		if tokens[i].Kind != sqltoken.Whitespace {
+			return tokens[i].Text == "::"
+		}
+	}
+	return false
+}
+
+// isSign reports whether tok is a unary + or - operator.
+func isSign(tok sqltoken.Token) bool {
+	return tok.Kind == sqltoken.Operator && (tok.Text == "+" || tok.Text == "-")
+}
+
+// startsWithLiteral reports whether the first token other than whitespace
+// and comments is a constant.
+func startsWithLiteral(tokens []sqltoken.Token) bool {
+	for _, tok := range tokens {
+		if tok.Kind != sqltoken.Whitespace && tok.Kind != sqltoken.Comment {
+			return tok.IsLiteral()
+		}
+	}
+	return false
+}
+
+// isElementStart reports whether the last token other than whitespace and
+// comments opens a list or separates its elements.
+func isElementStart(tokens []sqltoken.Token) bool {
+	for i := len(tokens) - 1; i >= 0; i-- {
+		if tokens[i].Kind != sqltoken.Whitespace && tokens[i].Kind != sqltoken.Comment {
+			return tokens[i].Text == "(" || tokens[i].Text == ","
+		}
+	}
+	return false
+}
+
+// statement returns the statement to record in span names and attributes.
+func (t *Tracer) statement(sql string) string {
+	if t.obfuscateSQL {
+		return ObfuscateSQL(sql)
+	}
+	return sql
+}
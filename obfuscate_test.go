@@ -0,0 +1,100 @@
+package otelpgx
+
+import "testing"
+
+func TestObfuscateSQL(t *testing.T) {
+	tests := []struct {
+		name string
+		stmt string
+		want string
+	}{
+		{
+			name: "String and number",
+			stmt: "SELECT * FROM users WHERE email = 'a@b.c' AND age > 42",
+			want: "SELECT * FROM users WHERE email = ? AND age > ?",
+		},
+		{
+			name: "Doubled quote",
+			stmt: "SELECT 'it''s' FROM t",
+			want: "SELECT ? FROM t",
+		},
+		{
+			name: "Escape string",
+			stmt: `SELECT E'it\'s', e'\\' FROM t`,
+			want: "SELECT ?, ? FROM t",
+		},
+		{
+			name: "Dollar-quoted strings",
+			stmt: "SELECT $$a 'b'$$, $tag$c $$ d$tag$ FROM t",
+			want: "SELECT ?, ? FROM t",
+		},
+		{
+			name: "Parameters kept",
+			stmt: "SELECT * FROM t WHERE id = $1 AND name = $2",
+			want: "SELECT * FROM t WHERE id = $1 AND name = $2",
+		},
+		{
+			name: "Casts",
+			stmt: "SELECT '2024-01-01'::date, 1.5e3::numeric, $1::text",
+			want: "SELECT ?::date, ?::numeric, $1::text",
+		},
+		{
+			name: "Hex, bit strings and bytea",
+			stmt: `SELECT 0xFF, X'1F', B'101', '\x0102'::bytea`,
+			want: "SELECT ?, ?, ?, ?::bytea",
+		},
+		{
+			name: "Numbers in identifiers kept",
+			stmt: "SELECT t1.c2 FROM t1",
+			want: "SELECT t1.c2 FROM t1",
+		},
+		{
+			name: "Quoted identifiers kept",
+			stmt: `SELECT "it's 1" FROM "t""2"`,
+			want: `SELECT "it's 1" FROM "t""2"`,
+		},
+		{
+			name: "Comments kept",
+			stmt: "-- name: GetUser :one\nSELECT /* don't /* nest */ 1 */ 'x'",
+			want: "-- name: GetUser :one\nSELECT /* don't /* nest */ 1 */ ?",
+		},
+		{
+			name: "IN list collapsed",
+			stmt: "SELECT * FROM t WHERE id IN (1, 2, 3) AND name NOT IN ('a','b')",
+			want: "SELECT * FROM t WHERE id IN (?) AND name NOT IN (?)",
+		},
+		{
+			name: "IN list of parameters collapsed",
+			stmt: "SELECT * FROM t WHERE id in ($1::int, $2::int)",
+			want: "SELECT * FROM t WHERE id in (?)",
+		},
+		{
+			name: "IN list of signed constants collapsed",
+			stmt: "SELECT * FROM t WHERE c IN (1, 2, -3) OR d IN (+1.5,- 2)",
+			want: "SELECT * FROM t WHERE c IN (?) OR d IN (?)",
+		},
+		{
+			name: "IN list of expressions kept",
+			stmt: "SELECT * FROM t WHERE c IN (1-2, 3)",
+			want: "SELECT * FROM t WHERE c IN (?-?, ?)",
+		},
+		{
+			name: "IN subquery kept",
+			stmt: "SELECT * FROM t WHERE id IN (SELECT id FROM u WHERE x = 1)",
+			want: "SELECT * FROM t WHERE id IN (SELECT id FROM u WHERE x = ?)",
+		},
+		{
+			name: "Unterminated string",
+			stmt: "SELECT 'secret",
+			want: "SELECT ?",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ObfuscateSQL(tt.stmt); got != tt.want {
+				t.Errorf("ObfuscateSQL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
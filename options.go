@@ -1,7 +1,10 @@
 package otelpgx
 
 import (
+	"time"
+
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -26,6 +29,18 @@ func WithTracerProvider(provider trace.TracerProvider) Option {
 	})
 }
 
+// WithTracerMeterProvider specifies a meter provider used to record the
+// db.client.operation.duration histogram for queries, batches, copies and
+// prepares, and the db.client.slow_queries counter, see
+// WithSlowQueryThreshold. Durations are recorded whether or not the operation
+// is sampled. Prepares are recorded as PREPARE operations.
+// If none is specified, no metrics are recorded by the Tracer.
+func WithTracerMeterProvider(provider metric.MeterProvider) Option {
+	return optionFunc(func(cfg *tracerConfig) {
+		cfg.mp = provider
+	})
+}
+
 // WithAttributes specifies additional attributes to be added to the span.
 func WithAttributes(attrs ...attribute.KeyValue) Option {
 	return optionFunc(func(cfg *tracerConfig) {
@@ -42,6 +57,17 @@ func WithTrimSQLInSpanName() Option {
 	})
 }
 
+// WithOperationTableSpanName names query spans "{db.operation} {db.name}.{db.sql.table}",
+// for example "SELECT app.users", as recommended by the OpenTelemetry database
+// semantic conventions, and sets the db.operation and db.sql.table attributes.
+// Prepare spans keep their "prepare " prefix. Statements other than SELECT,
+// INSERT, UPDATE, DELETE and MERGE are named as if this option was not set.
+func WithOperationTableSpanName() Option {
+	return optionFunc(func(cfg *tracerConfig) {
+		cfg.operationSpanName = true
+	})
+}
+
 // SpanNameFunc is a function that can be used to generate a span name for a
 // SQL. The function will be called with the SQL statement as a parameter.
 type SpanNameFunc func(stmt string) string
@@ -72,3 +98,132 @@ func WithIncludeQueryParameters() Option {
 		cfg.includeParams = true
 	})
 }
+
+// WithQueryParameterFormatter specifies how parameters included by
+// WithIncludeQueryParameters are rendered. If none is specified, the
+// formatter returned by NewParamFormatter(256) is used.
+func WithQueryParameterFormatter(formatter ParamFormatter) Option {
+	return optionFunc(func(cfg *tracerConfig) {
+		if formatter != nil {
+			cfg.paramFormatter = formatter
+		}
+	})
+}
+
+// WithQueryParameterRedactor replaces the parameters included by
+// WithIncludeQueryParameters for which redactor returns true with
+// [REDACTED], see RedactParams.
+func WithQueryParameterRedactor(redactor ParamRedactor) Option {
+	return optionFunc(func(cfg *tracerConfig) {
+		cfg.paramRedactor = redactor
+	})
+}
+
+// WithSQLObfuscation replaces the constants in SQL statements with ? before
+// they are used in span names and the db.statement attribute, see
+// ObfuscateSQL. Query parameters are not affected.
+func WithSQLObfuscation() Option {
+	return optionFunc(func(cfg *tracerConfig) {
+		cfg.obfuscateSQL = true
+	})
+}
+
+// WithSemConvStability selects which database semantic conventions are used
+// for span and metric attributes. If none is specified, it is read from the
+// OTEL_SEMCONV_STABILITY_OPT_IN environment variable, defaulting to
+// SemConvStabilityOld.
+func WithSemConvStability(stability SemConvStability) Option {
+	return optionFunc(func(cfg *tracerConfig) {
+		cfg.semConvStability = stability
+	})
+}
+
+// WithConnectionAttributes specifies the connection-level attributes added to
+// spans. They are computed once per connection and cached on it. If none are
+// specified, ConnAttributeServer, ConnAttributeUser and ConnAttributeDatabase
+// are used.
+func WithConnectionAttributes(fields ...ConnAttribute) Option {
+	return optionFunc(func(cfg *tracerConfig) {
+		cfg.connAttrFields = fields
+	})
+}
+
+// WithSQLCommenter makes Tracer.Querier append sqlcommenter comments carrying
+// the trace context to statements, so that entries of pg_stat_activity and
+// of the server log can be joined back to their trace.
+func WithSQLCommenter() Option {
+	return optionFunc(func(cfg *tracerConfig) {
+		cfg.sqlCommenter = true
+	})
+}
+
+// WithSpanFilter specifies filters consulted before starting query, batch
+// query, prepare, copy and connect spans. A span is only started if all the
+// filters return true. See SkipPingQueries, SkipTransactionControl and
+// SkipSessionStatements for built-in filters.
+func WithSpanFilter(filters ...SpanFilter) Option {
+	return optionFunc(func(cfg *tracerConfig) {
+		cfg.spanFilters = append(cfg.spanFilters, filters...)
+	})
+}
+
+// WithSlowQueryThreshold marks the queries, batches and copies taking longer
+// than threshold as slow: their span gets the pgx.slow_query attribute and a
+// slow_query event, the db.client.slow_queries counter is incremented when a
+// meter provider is configured, and the statement is logged when
+// WithSlowQueryLogger is used. Slow queries are detected whether or not the
+// operation is sampled.
+func WithSlowQueryThreshold(threshold time.Duration) Option {
+	return optionFunc(func(cfg *tracerConfig) {
+		cfg.slowQueryThreshold = threshold
+	})
+}
+
+// WithSlowQueryLogger logs slow queries at the warn level through a Logger
+// configured with opts, as for NewTraceLogger. See WithSlowQueryThreshold.
+func WithSlowQueryLogger(opts ...LoggerOption) Option {
+	return optionFunc(func(cfg *tracerConfig) {
+		l := newLogger(opts...)
+		cfg.slowQueryLogger = &l
+	})
+}
+
+// WithBatchQueryEvents records the queries of a batch as events on the batch
+// span instead of child spans, which keeps traces of large batches small.
+func WithBatchQueryEvents() Option {
+	return optionFunc(func(cfg *tracerConfig) {
+		cfg.batchQueryEvents = true
+	})
+}
+
+// WithErrorClassifiers specifies the classifiers deciding the span status of
+// failed operations, consulted in order. Errors no classifier has an opinion
+// about set the span status to Error. If none are specified, ClassifyNoRows
+// is used.
+func WithErrorClassifiers(classifiers ...ErrorClassifier) Option {
+	return optionFunc(func(cfg *tracerConfig) {
+		cfg.errorClassifiers = classifiers
+	})
+}
+
+// WithErrorType specifies how the error.type attribute of failed operations
+// is computed. If none is specified, ErrorTypeSQLState is used.
+func WithErrorType(fn ErrorTypeFunc) Option {
+	return optionFunc(func(cfg *tracerConfig) {
+		if fn != nil {
+			cfg.errorType = fn
+		}
+	})
+}
+
+// WithPgErrorDetails records the fields of PostgreSQL errors other than the
+// SQLSTATE code, such as the detail, hint, table and constraint name, as
+// pgx.error.* attributes of the spans of failed operations. With
+// WithSQLObfuscation, the key and row values in error details are replaced
+// with ?, see ObfuscateErrorDetail, as are the constants of the SQL
+// statements quoted in the error context.
+func WithPgErrorDetails() Option {
+	return optionFunc(func(cfg *tracerConfig) {
+		cfg.pgErrorDetails = true
+	})
+}
@@ -0,0 +1,230 @@
+package otelpgx
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/tracelog"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/global"
+)
+
+var _ tracelog.Logger = (*OTelLogger)(nil)
+
+// OTelLogger is a tracelog.Logger emitting the pgx trace logs through the
+// OpenTelemetry Logs API, so they can be exported along with traces and
+// metrics. Records are emitted with the context of the logged operation,
+// which carries its span. Install it with a tracelog.TraceLog:
+//
+//	cfg.ConnConfig.Tracer = &tracelog.TraceLog{
+//		Logger:   otelpgx.NewOTelLogger(),
+//		LogLevel: tracelog.LogLevelInfo,
+//	}
+type OTelLogger struct {
+	logger         log.Logger
+	paramFormatter ParamFormatter
+	paramRedactor  ParamRedactor
+}
+
+type otelLoggerConfig struct {
+	provider       log.LoggerProvider
+	paramFormatter ParamFormatter
+	paramRedactor  ParamRedactor
+}
+
+// OTelLoggerOption specifies OTelLogger configuration options.
+type OTelLoggerOption func(*otelLoggerConfig)
+
+// WithLoggerProvider specifies the logger provider used by an OTelLogger.
+// If none is specified, the global provider is used.
+func WithLoggerProvider(provider log.LoggerProvider) OTelLoggerOption {
+	return func(cfg *otelLoggerConfig) {
+		if provider != nil {
+			cfg.provider = provider
+		}
+	}
+}
+
+// WithOTelLogQueryParameterFormatter specifies how the query arguments of
+// the args attribute are rendered. By default, they are rendered by
+// NewParamFormatter(256).
+func WithOTelLogQueryParameterFormatter(formatter ParamFormatter) OTelLoggerOption {
+	return func(cfg *otelLoggerConfig) {
+		if formatter != nil {
+			cfg.paramFormatter = formatter
+		}
+	}
+}
+
+// WithOTelLogQueryParameterRedactor replaces the query arguments for which
+// redactor returns true with [REDACTED] in the args attribute, see
+// RedactParams.
+func WithOTelLogQueryParameterRedactor(redactor ParamRedactor) OTelLoggerOption {
+	return func(cfg *otelLoggerConfig) {
+		cfg.paramRedactor = redactor
+	}
+}
+
+// NewOTelLogger returns a new OTelLogger.
+func NewOTelLogger(opts ...OTelLoggerOption) *OTelLogger {
+	cfg := &otelLoggerConfig{
+		provider:       global.GetLoggerProvider(),
+		paramFormatter: NewParamFormatter(defaultParamMaxLength),
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return &OTelLogger{
+		logger:         cfg.provider.Logger(tracerName, log.WithInstrumentationVersion(findOwnImportedVersion())),
+		paramFormatter: cfg.paramFormatter,
+		paramRedactor:  cfg.paramRedactor,
+	}
+}
+
+// Log emits a record with msg as body and data as attributes. The query
+// arguments, under the args key, are rendered like WithIncludeQueryParameters.
+func (l *OTelLogger) Log(ctx context.Context, level tracelog.LogLevel, msg string, data map[string]any) {
+	severity, ok := otelSeverity(level)
+	if !ok {
+		return
+	}
+
+	var record log.Record
+	record.SetSeverity(severity)
+	if !l.logger.Enabled(ctx, record) {
+		return
+	}
+
+	now := time.Now()
+	record.SetTimestamp(now)
+	record.SetObservedTimestamp(now)
+	record.SetSeverityText(level.String())
+	record.SetBody(log.StringValue(msg))
+
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	attrs := make([]log.KeyValue, len(keys))
+	for i, k := range keys {
+		if args, ok := data[k].([]any); ok && k == "args" {
+			params := formatParams(args, l.paramFormatter, l.paramRedactor)
+			attrs[i] = log.KeyValue{Key: k, Value: otelLogValue(params)}
+			continue
+		}
+		attrs[i] = log.KeyValue{Key: k, Value: otelLogValue(data[k])}
+	}
+	record.AddAttributes(attrs...)
+
+	l.logger.Emit(ctx, record)
+}
+
+// otelSeverity returns the OpenTelemetry severity of level, or false if
+// nothing is logged at level.
+func otelSeverity(level tracelog.LogLevel) (log.Severity, bool) {
+	switch level {
+	case tracelog.LogLevelTrace:
+		return log.SeverityTrace, true
+	case tracelog.LogLevelDebug:
+		return log.SeverityDebug, true
+	case tracelog.LogLevelInfo:
+		return log.SeverityInfo, true
+	case tracelog.LogLevelWarn:
+		return log.SeverityWarn, true
+	case tracelog.LogLevelError:
+		return log.SeverityError, true
+	default:
+		return log.SeverityUndefined, false
+	}
+}
+
+// otelLogValue converts a value of the pgx data map, such as the query
+// arguments, to a log attribute value. Durations are converted to
+// milliseconds, and unsigned integers overflowing an int64 to strings.
+func otelLogValue(v any) log.Value {
+	switch v := v.(type) {
+	case nil:
+		return log.Value{}
+	case string:
+		return log.StringValue(v)
+	case bool:
+		return log.BoolValue(v)
+	case int:
+		return log.IntValue(v)
+	case int8:
+		return log.Int64Value(int64(v))
+	case int16:
+		return log.Int64Value(int64(v))
+	case int32:
+		return log.Int64Value(int64(v))
+	case int64:
+		return log.Int64Value(v)
+	case uint8:
+		return log.Int64Value(int64(v))
+	case uint16:
+		return log.Int64Value(int64(v))
+	case uint32:
+		return log.Int64Value(int64(v))
+	case uint:
+		return otelLogUint64Value(uint64(v))
+	case uint64:
+		return otelLogUint64Value(v)
+	case float32:
+		return log.Float64Value(float64(v))
+	case float64:
+		return log.Float64Value(v)
+	case time.Duration:
+		return log.Float64Value(milliseconds(v))
+	case time.Time:
+		return log.StringValue(v.Format(time.RFC3339Nano))
+	case []byte:
+		return log.BytesValue(v)
+	case []string:
+		values := make([]log.Value, len(v))
+		for i, elem := range v {
+			values[i] = log.StringValue(elem)
+		}
+		return log.SliceValue(values...)
+	case []any:
+		values := make([]log.Value, len(v))
+		for i, elem := range v {
+			values[i] = otelLogValue(elem)
+		}
+		return log.SliceValue(values...)
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		kvs := make([]log.KeyValue, len(keys))
+		for i, k := range keys {
+			kvs[i] = log.KeyValue{Key: k, Value: otelLogValue(v[k])}
+		}
+		return log.MapValue(kvs...)
+	case error:
+		return log.StringValue(v.Error())
+	case fmt.Stringer:
+		return log.StringValue(v.String())
+	default:
+		return log.StringValue(fmt.Sprint(v))
+	}
+}
+
+// otelLogUint64Value converts v to an Int64 log value, or to a string one if
+// it overflows an int64.
+func otelLogUint64Value(v uint64) log.Value {
+	if v > math.MaxInt64 {
+		return log.StringValue(strconv.FormatUint(v, 10))
+	}
+	return log.Int64Value(int64(v))
+}
@@ -0,0 +1,142 @@
+package otelpgx
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/tracelog"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/embedded"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// fakeLogProvider returns its logger, which records the records it emits
+// with their context.
+type fakeLogProvider struct {
+	embedded.LoggerProvider
+
+	logger *fakeLogger
+}
+
+func (p fakeLogProvider) Logger(string, ...log.LoggerOption) log.Logger { return p.logger }
+
+type fakeLogger struct {
+	embedded.Logger
+
+	minSeverity log.Severity
+	records     []log.Record
+	ctxs        []context.Context
+}
+
+func (l *fakeLogger) Enabled(_ context.Context, r log.Record) bool {
+	return r.Severity() >= l.minSeverity
+}
+
+func (l *fakeLogger) Emit(ctx context.Context, r log.Record) {
+	l.records = append(l.records, r)
+	l.ctxs = append(l.ctxs, ctx)
+}
+
+func TestOTelLogger(t *testing.T) {
+	logger := &fakeLogger{minSeverity: log.SeverityInfo}
+	l := NewOTelLogger(WithLoggerProvider(fakeLogProvider{logger: logger}))
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: trace.TraceID{0x01}, SpanID: trace.SpanID{0x02}})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	l.Log(ctx, tracelog.LogLevelDebug, "Query", nil)
+	l.Log(ctx, tracelog.LogLevelNone, "Query", nil)
+	l.Log(ctx, tracelog.LogLevelError, "Query", map[string]any{
+		"sql":        "SELECT $1",
+		"args":       []any{int32(42), nil},
+		"time":       1500 * time.Microsecond,
+		"commandTag": pgconn.NewCommandTag("SELECT 1"),
+		"err":        errors.New("boom"),
+		"pid":        uint32(7),
+	})
+
+	if len(logger.records) != 1 {
+		t.Fatalf("got %d records, want 1", len(logger.records))
+	}
+
+	r := logger.records[0]
+	if r.Severity() != log.SeverityError || r.SeverityText() != "error" {
+		t.Errorf("severity = %v %q, want ERROR", r.Severity(), r.SeverityText())
+	}
+	if r.Body().AsString() != "Query" {
+		t.Errorf("body = %v, want Query", r.Body())
+	}
+	if got := trace.SpanContextFromContext(logger.ctxs[0]); !got.Equal(sc) {
+		t.Errorf("emitted with span %v, want %v", got.SpanID(), sc.SpanID())
+	}
+
+	want := map[string]log.Value{
+		"sql":        log.StringValue("SELECT $1"),
+		"args":       log.SliceValue(log.StringValue("42"), log.StringValue("NULL")),
+		"time":       log.Float64Value(1.5),
+		"commandTag": log.StringValue("SELECT 1"),
+		"err":        log.StringValue("boom"),
+		"pid":        log.Int64Value(7),
+	}
+
+	var keys []string
+	r.WalkAttributes(func(kv log.KeyValue) bool {
+		keys = append(keys, kv.Key)
+		if !kv.Value.Equal(want[kv.Key]) {
+			t.Errorf("%s = %v, want %v", kv.Key, kv.Value, want[kv.Key])
+		}
+		return true
+	})
+	if len(keys) != len(want) {
+		t.Errorf("got attributes %v, want %d", keys, len(want))
+	}
+}
+
+func TestOTelLogger_args(t *testing.T) {
+	logger := &fakeLogger{}
+	l := NewOTelLogger(
+		WithLoggerProvider(fakeLogProvider{logger: logger}),
+		WithOTelLogQueryParameterFormatter(NewParamFormatter(3)),
+		WithOTelLogQueryParameterRedactor(RedactParams("$2")),
+	)
+
+	l.Log(context.Background(), tracelog.LogLevelInfo, "Query", map[string]any{
+		"args": []any{pgx.QueryExecModeExec, "abcdef", "secret"},
+	})
+
+	want := log.SliceValue(log.StringValue("abc..."), log.StringValue(redactedParam))
+	logger.records[0].WalkAttributes(func(kv log.KeyValue) bool {
+		if !kv.Value.Equal(want) {
+			t.Errorf("%s = %v, want %v", kv.Key, kv.Value, want)
+		}
+		return true
+	})
+}
+
+func TestOTelLogValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  log.Value
+	}{
+		{name: "Uint", value: uint(42), want: log.Int64Value(42)},
+		{name: "Uint64", value: uint64(42), want: log.Int64Value(42)},
+		{name: "Overflowing uint64", value: uint64(math.MaxUint64), want: log.StringValue("18446744073709551615")},
+		{name: "Strings", value: []string{"id", "name"}, want: log.SliceValue(log.StringValue("id"), log.StringValue("name"))},
+		{name: "Duration", value: 2500 * time.Microsecond, want: log.Float64Value(2.5)},
+		{name: "Nested duration", value: []any{time.Second}, want: log.SliceValue(log.Float64Value(1000))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := otelLogValue(tt.value); !got.Equal(tt.want) {
+				t.Errorf("otelLogValue(%v) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
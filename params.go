@@ -0,0 +1,197 @@
+package otelpgx
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// defaultParamMaxLength is the length after which the default
+	// ParamFormatter truncates parameters.
+	defaultParamMaxLength = 256
+
+	// redactedParam replaces the value of redacted parameters.
+	redactedParam = "[REDACTED]"
+)
+
+// ParamFormatter renders a query parameter for the pgx.query.parameters
+// attribute.
+type ParamFormatter func(arg any) string
+
+// ParamRedactor reports whether a query parameter must be redacted. For
+// positional parameters, position is 1 for $1, 2 for $2, and so on, and name
+// is empty. For pgx.NamedArgs and pgx.StrictNamedArgs parameters, position is
+// 0 and name is the argument name.
+type ParamRedactor func(position int, name string) bool
+
+// RedactParams returns a ParamRedactor redacting the given parameters, which
+// are either positions such as "$3", or names of pgx.NamedArgs arguments such
+// as "password".
+func RedactParams(params ...string) ParamRedactor {
+	positions := make(map[int]bool)
+	names := make(map[string]bool)
+
+	for _, p := range params {
+		if n, err := strconv.Atoi(strings.TrimPrefix(p, "$")); err == nil && strings.HasPrefix(p, "$") {
+			positions[n] = true
+		} else {
+			names[p] = true
+		}
+	}
+
+	return func(position int, name string) bool {
+		if name != "" {
+			return names[name]
+		}
+		return positions[position]
+	}
+}
+
+// NewParamFormatter returns a ParamFormatter which:
+//   - renders nil values and nil pointers as NULL,
+//   - renders driver.Valuer values, which include the pgtype types, through
+//     their Value method,
+//   - renders byte slices as their length, such as "[16 bytes]",
+//   - renders time.Time values in RFC 3339 format,
+//   - truncates values longer than maxLength bytes, if maxLength is positive.
+func NewParamFormatter(maxLength int) ParamFormatter {
+	return func(arg any) string {
+		return truncateParam(formatParam(arg), maxLength)
+	}
+}
+
+func formatParam(arg any) string {
+	if isNil(arg) {
+		return "NULL"
+	}
+
+	if valuer, ok := arg.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return fmt.Sprintf("<error: %v>", err)
+		}
+		if _, ok := v.(driver.Valuer); ok {
+			// Do not loop on Value methods returning themselves.
+			return fmt.Sprintf("%+v", v)
+		}
+		return formatParam(v)
+	}
+
+	switch v := arg.(type) {
+	case string:
+		return v
+	case []byte:
+		return fmt.Sprintf("[%d bytes]", len(v))
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprintf("%+v", v)
+	}
+}
+
+func isNil(arg any) bool {
+	if arg == nil {
+		return true
+	}
+
+	switch v := reflect.ValueOf(arg); v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
+
+// truncateParam truncates s to at most maxLength bytes without splitting a
+// rune.
+func truncateParam(s string, maxLength int) string {
+	if maxLength <= 0 || len(s) <= maxLength {
+		return s
+	}
+
+	n := maxLength
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return s[:n] + "..."
+}
+
+// paramsAttribute returns the pgx.query.parameters attribute for the
+// arguments of a query, see formatParams.
+func (t *Tracer) paramsAttribute(args []any) attribute.KeyValue {
+	return QueryParametersKey.StringSlice(formatParams(args, t.paramFormatter, t.paramRedactor))
+}
+
+// formatParams renders the arguments of a query with formatter, replacing
+// the ones redactor returns true for, if not nil, with [REDACTED]. Leading
+// query options such as pgx.QueryExecMode are skipped, along with the
+// sqlCommentRewriter inserted by Tracer.Querier unless it wraps a query
+// rewriter, so that positions match $1, $2, and so on. pgx.NamedArgs are
+// rendered as name=value sorted by name.
+func formatParams(args []any, formatter ParamFormatter, redactor ParamRedactor) []string {
+	for len(args) > 0 {
+		if r, ok := args[0].(sqlCommentRewriter); !isQueryOption(args[0]) && (!ok || r.inner != nil) {
+			break
+		}
+		args = args[1:]
+	}
+
+	format := func(position int, name string, arg any) string {
+		if redactor != nil && redactor(position, name) {
+			return redactedParam
+		}
+		return formatter(arg)
+	}
+
+	ss := make([]string, 0, len(args))
+
+	for i, arg := range args {
+		if r, ok := arg.(sqlCommentRewriter); ok {
+			arg = r.inner
+		}
+
+		var named map[string]any
+		switch a := arg.(type) {
+		case pgx.NamedArgs:
+			named = a
+		case pgx.StrictNamedArgs:
+			named = a
+		default:
+			ss = append(ss, format(i+1, "", arg))
+			continue
+		}
+
+		names := make([]string, 0, len(named))
+		for name := range named {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			ss = append(ss, name+"="+format(0, name, named[name]))
+		}
+	}
+
+	return ss
+}
+
+// isQueryOption reports whether arg is one of the options pgx accepts before
+// the query arguments.
+func isQueryOption(arg any) bool {
+	switch arg.(type) {
+	case pgx.QueryExecMode, pgx.QueryResultFormats, pgx.QueryResultFormatsByOID:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,78 @@
+package otelpgx
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestTracer_paramsAttribute(t *testing.T) {
+	var nilTime *time.Time
+
+	tests := []struct {
+		name   string
+		tracer *Tracer
+		args   []any
+		want   []string
+	}{
+		{
+			name:   "Basic types",
+			tracer: NewTracer(),
+			args:   []any{1, "a", nil, nilTime, []byte("secret")},
+			want:   []string{"1", "a", "NULL", "NULL", "[6 bytes]"},
+		},
+		{
+			name:   "pgtype values",
+			tracer: NewTracer(),
+			args: []any{
+				pgtype.Text{String: "b", Valid: true},
+				pgtype.Int8{Int64: 7, Valid: true},
+				pgtype.Int8{},
+				pgtype.Timestamptz{Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Valid: true},
+			},
+			want: []string{"b", "7", "NULL", "2024-01-02T03:04:05Z"},
+		},
+		{
+			name:   "Truncated",
+			tracer: NewTracer(WithQueryParameterFormatter(NewParamFormatter(3))),
+			args:   []any{"abcdef", "ab", "aaé"},
+			want:   []string{"abc...", "ab", "aa..."},
+		},
+		{
+			name:   "Query options skipped",
+			tracer: NewTracer(WithQueryParameterRedactor(RedactParams("$2"))),
+			args:   []any{pgx.QueryExecModeSimpleProtocol, "user", "hunter2"},
+			want:   []string{"user", redactedParam},
+		},
+		{
+			name:   "sqlcommenter rewriter skipped",
+			tracer: NewTracer(WithQueryParameterRedactor(RedactParams("$1"))),
+			args:   withSQLCommentRewriter([]any{pgx.QueryExecModeExec, "secret", "b"}),
+			want:   []string{redactedParam, "b"},
+		},
+		{
+			name:   "sqlcommenter rewriter wrapping named arguments",
+			tracer: NewTracer(WithQueryParameterRedactor(RedactParams("password"))),
+			args:   withSQLCommentRewriter([]any{pgx.NamedArgs{"password": "hunter2"}}),
+			want:   []string{"password=" + redactedParam},
+		},
+		{
+			name:   "Named arguments",
+			tracer: NewTracer(WithQueryParameterRedactor(RedactParams("password"))),
+			args:   []any{pgx.NamedArgs{"user": "u", "password": "hunter2"}},
+			want:   []string{"password=" + redactedParam, "user=u"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.tracer.paramsAttribute(tt.args).Value.AsStringSlice()
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("paramsAttribute() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
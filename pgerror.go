@@ -0,0 +1,170 @@
+package otelpgx
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// ErrorSeverityKey represents the severity of a PostgreSQL error, such as
+	// ERROR or FATAL.
+	ErrorSeverityKey = attribute.Key("pgx.error.severity")
+	// ErrorMessageKey represents the primary message of a PostgreSQL error.
+	ErrorMessageKey = attribute.Key("pgx.error.message")
+	// ErrorDetailKey represents the detail message of a PostgreSQL error.
+	ErrorDetailKey = attribute.Key("pgx.error.detail")
+	// ErrorHintKey represents the hint of a PostgreSQL error.
+	ErrorHintKey = attribute.Key("pgx.error.hint")
+	// ErrorPositionKey represents the 1-based character position of the
+	// error in the statement.
+	ErrorPositionKey = attribute.Key("pgx.error.position")
+	// ErrorWhereKey represents the context in which a PostgreSQL error
+	// occurred, such as the PL/pgSQL call stack.
+	ErrorWhereKey = attribute.Key("pgx.error.where")
+	// ErrorSchemaKey represents the schema of the object a PostgreSQL error
+	// is about.
+	ErrorSchemaKey = attribute.Key("pgx.error.schema")
+	// ErrorTableKey represents the table a PostgreSQL error is about.
+	ErrorTableKey = attribute.Key("pgx.error.table")
+	// ErrorColumnKey represents the column a PostgreSQL error is about.
+	ErrorColumnKey = attribute.Key("pgx.error.column")
+	// ErrorDataTypeKey represents the data type a PostgreSQL error is about.
+	ErrorDataTypeKey = attribute.Key("pgx.error.data_type")
+	// ErrorConstraintKey represents the constraint a PostgreSQL error is
+	// about, such as the violated unique constraint.
+	ErrorConstraintKey = attribute.Key("pgx.error.constraint")
+)
+
+// pgErrorAttributes returns the attributes describing the PostgreSQL error
+// wrapped by err, if any: its SQLSTATE code and, with WithPgErrorDetails,
+// its other non-empty fields.
+func (t *Tracer) pgErrorAttributes(err error) []attribute.KeyValue {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return nil
+	}
+
+	attrs := []attribute.KeyValue{SQLStateKey.String(pgErr.Code)}
+	if !t.pgErrorDetails {
+		return attrs
+	}
+
+	fields := []struct {
+		key   attribute.Key
+		value string
+	}{
+		{ErrorSeverityKey, pgErr.Severity},
+		{ErrorMessageKey, pgErr.Message},
+		{ErrorDetailKey, t.errorDetail(pgErr.Detail)},
+		{ErrorHintKey, pgErr.Hint},
+		{ErrorWhereKey, t.errorWhere(pgErr.Where)},
+		{ErrorSchemaKey, pgErr.SchemaName},
+		{ErrorTableKey, pgErr.TableName},
+		{ErrorColumnKey, pgErr.ColumnName},
+		{ErrorDataTypeKey, pgErr.DataTypeName},
+		{ErrorConstraintKey, pgErr.ConstraintName},
+	}
+	for _, f := range fields {
+		if f.value != "" {
+			attrs = append(attrs, f.key.String(f.value))
+		}
+	}
+
+	if pgErr.Position > 0 {
+		attrs = append(attrs, ErrorPositionKey.Int(int(pgErr.Position)))
+	}
+
+	return attrs
+}
+
+// errorDetail returns the error detail to record, with the values of keys
+// and rows replaced with ? when the SQL obfuscation is enabled.
+func (t *Tracer) errorDetail(detail string) string {
+	if t.obfuscateSQL {
+		return ObfuscateErrorDetail(detail)
+	}
+	return detail
+}
+
+// errorWhere returns the error context to record, with the SQL statements it
+// quotes obfuscated when the SQL obfuscation is enabled.
+func (t *Tracer) errorWhere(where string) string {
+	if !t.obfuscateSQL || where == "" {
+		return where
+	}
+
+	lines := strings.Split(where, "\n")
+	for i, line := range lines {
+		const prefix = `SQL statement "`
+		if strings.HasPrefix(line, prefix) && strings.HasSuffix(line, `"`) && len(line) > len(prefix) {
+			lines[i] = prefix + ObfuscateSQL(line[len(prefix):len(line)-1]) + `"`
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// errorDetailValueMarkers precede the parenthesized values PostgreSQL puts in
+// error details, as in "Key (email)=(a@example.com) already exists." or
+// "Failing row contains (1, null)."
+var errorDetailValueMarkers = []string{")=(", "row contains ("}
+
+// ObfuscateErrorDetail replaces the key and row values PostgreSQL includes in
+// error details, such as unique and not-null violations, with ?. For example
+// "Key (email)=(a@example.com) already exists." becomes
+// "Key (email)=(?) already exists."
+func ObfuscateErrorDetail(detail string) string {
+	var b strings.Builder
+
+	for {
+		start, end := nextErrorDetailValue(detail)
+		if start < 0 {
+			break
+		}
+
+		b.WriteString(detail[:start])
+		b.WriteByte('?')
+		detail = detail[end:]
+	}
+
+	if b.Len() == 0 {
+		return detail
+	}
+
+	b.WriteString(detail)
+	return b.String()
+}
+
+// nextErrorDetailValue returns the bounds of the first parenthesized value in
+// detail, excluding the parentheses, or -1 if there is none.
+func nextErrorDetailValue(detail string) (start, end int) {
+	start = -1
+	for _, marker := range errorDetailValueMarkers {
+		if i := strings.Index(detail, marker); i >= 0 && (start < 0 || i+len(marker) < start) {
+			start = i + len(marker)
+		}
+	}
+	if start < 0 {
+		return -1, -1
+	}
+
+	// Values may contain parentheses, such as row values, so the closing
+	// parenthesis is the one balancing the opening one.
+	depth := 1
+	for i := start; i < len(detail); i++ {
+		switch detail[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return start, i
+			}
+		}
+	}
+
+	return start, len(detail)
+}
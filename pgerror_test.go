@@ -0,0 +1,110 @@
+package otelpgx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestObfuscateErrorDetail(t *testing.T) {
+	tests := []struct {
+		detail string
+		want   string
+	}{
+		{detail: "", want: ""},
+		{detail: "Key (email)=(a@example.com) already exists.", want: "Key (email)=(?) already exists."},
+		{detail: "Key (a, b)=(1, x) already exists.", want: "Key (a, b)=(?) already exists."},
+		{detail: "Key (lower(email::text))=(a(b)c) already exists.", want: "Key (lower(email::text))=(?) already exists."},
+		{detail: `Key (user_id)=(42) is not present in table "users".`, want: `Key (user_id)=(?) is not present in table "users".`},
+		{detail: "Failing row contains (1, null, secret).", want: "Failing row contains (?)."},
+		{detail: "Key (id)=(1", want: "Key (id)=(?"},
+		{detail: "Process 42 waits for ShareLock on transaction 7.", want: "Process 42 waits for ShareLock on transaction 7."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.detail, func(t *testing.T) {
+			if got := ObfuscateErrorDetail(tt.detail); got != tt.want {
+				t.Errorf("ObfuscateErrorDetail() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTracer_pgErrorDetails(t *testing.T) {
+	pgErr := &pgconn.PgError{
+		Severity:       "ERROR",
+		Code:           "23505",
+		Message:        `duplicate key value violates unique constraint "users_email_key"`,
+		Detail:         "Key (email)=(a@example.com) already exists.",
+		Where:          "SQL statement \"INSERT INTO users VALUES ('a@example.com')\"\nPL/pgSQL function add_user() line 3 at SQL statement",
+		SchemaName:     "public",
+		TableName:      "users",
+		ConstraintName: "users_email_key",
+	}
+
+	tests := []struct {
+		name  string
+		opts  []Option
+		want  map[attribute.Key]attribute.Value
+		unset []attribute.Key
+	}{
+		{
+			name:  "Disabled",
+			want:  map[attribute.Key]attribute.Value{SQLStateKey: attribute.StringValue("23505")},
+			unset: []attribute.Key{ErrorDetailKey, ErrorConstraintKey},
+		},
+		{
+			name: "Enabled",
+			opts: []Option{WithPgErrorDetails()},
+			want: map[attribute.Key]attribute.Value{
+				SQLStateKey:        attribute.StringValue("23505"),
+				ErrorSeverityKey:   attribute.StringValue("ERROR"),
+				ErrorMessageKey:    attribute.StringValue(pgErr.Message),
+				ErrorDetailKey:     attribute.StringValue(pgErr.Detail),
+				ErrorWhereKey:      attribute.StringValue(pgErr.Where),
+				ErrorSchemaKey:     attribute.StringValue("public"),
+				ErrorTableKey:      attribute.StringValue("users"),
+				ErrorConstraintKey: attribute.StringValue("users_email_key"),
+			},
+			unset: []attribute.Key{ErrorHintKey, ErrorColumnKey, ErrorPositionKey},
+		},
+		{
+			name: "Obfuscated",
+			opts: []Option{WithPgErrorDetails(), WithSQLObfuscation()},
+			want: map[attribute.Key]attribute.Value{
+				ErrorDetailKey: attribute.StringValue("Key (email)=(?) already exists."),
+				ErrorWhereKey:  attribute.StringValue("SQL statement \"INSERT INTO users VALUES (?)\"\nPL/pgSQL function add_user() line 3 at SQL statement"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			tr := NewTracer(append([]Option{WithTracerProvider(tp)}, tt.opts...)...)
+
+			ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+			ctx = tr.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "SELECT add_user($1)"})
+			tr.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: pgErr})
+			parent.End()
+
+			attrs := attribute.NewSet(recorder.Ended()[0].Attributes()...)
+			for key, want := range tt.want {
+				if got, ok := attrs.Value(key); !ok || got != want {
+					t.Errorf("%v = %v, want %v", key, got.Emit(), want.Emit())
+				}
+			}
+			for _, key := range tt.unset {
+				if attrs.HasValue(key) {
+					t.Errorf("unexpected attribute %v", key)
+				}
+			}
+		})
+	}
+}
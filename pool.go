@@ -0,0 +1,149 @@
+package otelpgx
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// PoolEmptyKey represents whether the pool had no idle connections when
+	// an acquire started.
+	PoolEmptyKey = attribute.Key("pgx.pool.empty")
+	// PoolAcquireWaitKey represents the time in milliseconds spent waiting
+	// for a connection from the pool.
+	PoolAcquireWaitKey = attribute.Key("pgx.pool.acquire.wait_ms")
+	// PoolConnHoldKey represents the time in milliseconds a connection was
+	// held between acquire and release.
+	PoolConnHoldKey = attribute.Key("pgx.pool.conn.hold_ms")
+)
+
+var (
+	_ pgxpool.AcquireTracer = (*Tracer)(nil)
+	_ pgxpool.ReleaseTracer = (*Tracer)(nil)
+)
+
+type acquireStartKey struct{}
+
+// acquireStart is stored in the context by TraceAcquireStart.
+type acquireStart struct {
+	parent trace.SpanContext
+	start  time.Time
+}
+
+// acquiredConnKey is the pgconn.PgConn custom data key under which the
+// Tracers remember the acquire of a pool connection until its release. The
+// entry goes away with the connection if it is hijacked or destroyed without
+// being released.
+const acquiredConnKey = "github.com/piusalfred/otelpgx.acquiredConn"
+
+// acquiredConn is what a Tracer remembers about a connection between
+// TraceAcquireEnd and TraceRelease.
+type acquiredConn struct {
+	parent     trace.SpanContext
+	acquiredAt time.Time
+}
+
+// poolAttributes returns the attributes describing the server and the
+// database of pool. They are computed once per pool, as pool.Config returns
+// a deep copy of the pool config.
+func (t *Tracer) poolAttributes(pool *pgxpool.Pool) []attribute.KeyValue {
+	if attrs, ok := t.poolAttrs.Load(pool); ok {
+		return attrs.([]attribute.KeyValue)
+	}
+
+	attrs, _ := t.poolAttrs.LoadOrStore(pool, t.connConfigAttributes(pool.Config().ConnConfig))
+	return attrs.([]attribute.KeyValue)
+}
+
+// TraceAcquireStart is called at the beginning of Acquire. The returned
+// context is used for the rest of the call and will be passed to
+// TraceAcquireEnd.
+func (t *Tracer) TraceAcquireStart(ctx context.Context, pool *pgxpool.Pool, _ pgxpool.TraceAcquireStartData) context.Context {
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		return ctx
+	}
+
+	opts := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(t.attrs...),
+	}
+
+	if pool != nil {
+		opts = append(opts, trace.WithAttributes(PoolEmptyKey.Bool(pool.Stat().IdleConns() == 0)))
+		opts = append(opts, trace.WithAttributes(t.poolAttributes(pool)...))
+	}
+
+	ctx = context.WithValue(ctx, acquireStartKey{}, acquireStart{
+		parent: trace.SpanContextFromContext(ctx),
+		start:  time.Now(),
+	})
+	ctx, _ = t.tracer.Start(ctx, "pool.acquire", opts...)
+
+	return ctx
+}
+
+// TraceAcquireEnd is called when a connection has been acquired.
+func (t *Tracer) TraceAcquireEnd(ctx context.Context, _ *pgxpool.Pool, data pgxpool.TraceAcquireEndData) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	now := time.Now()
+	as, ok := ctx.Value(acquireStartKey{}).(acquireStart)
+	if ok {
+		span.SetAttributes(PoolAcquireWaitKey.Float64(milliseconds(now.Sub(as.start))))
+	}
+
+	t.recordError(ctx, span, data.Err)
+
+	if ok && data.Err == nil && data.Conn != nil {
+		// The acquire span is about to end, so the release is attached to
+		// the caller's span instead.
+		if data := data.Conn.PgConn().CustomData(); data != nil {
+			acquired, _ := data[acquiredConnKey].(map[*Tracer]acquiredConn)
+			if acquired == nil {
+				acquired = make(map[*Tracer]acquiredConn)
+				data[acquiredConnKey] = acquired
+			}
+			acquired[t] = acquiredConn{parent: as.parent, acquiredAt: now}
+		}
+	}
+
+	span.End()
+}
+
+// TraceRelease is called at the beginning of Release.
+func (t *Tracer) TraceRelease(_ *pgxpool.Pool, data pgxpool.TraceReleaseData) {
+	if data.Conn == nil {
+		return
+	}
+
+	acquired, _ := data.Conn.PgConn().CustomData()[acquiredConnKey].(map[*Tracer]acquiredConn)
+	ac, ok := acquired[t]
+	if !ok {
+		return
+	}
+	delete(acquired, t)
+
+	ctx := trace.ContextWithSpanContext(context.Background(), ac.parent)
+
+	opts := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(t.attrs...),
+		trace.WithAttributes(PoolConnHoldKey.Float64(milliseconds(time.Since(ac.acquiredAt)))),
+	}
+	opts = append(opts, trace.WithAttributes(t.connAttributes(data.Conn)...))
+
+	_, span := t.tracer.Start(ctx, "pool.release", opts...)
+	span.End()
+}
+
+// milliseconds converts d to fractional milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
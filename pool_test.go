@@ -0,0 +1,113 @@
+package otelpgx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracer_pool(t *testing.T) {
+	server := newTestServer(t)
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tr := NewTracer(WithTracerProvider(tp), WithSemConvStability(SemConvStabilityOld))
+
+	config, err := pgxpool.ParseConfig("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.ConnConfig = server.connConfig(t)
+	config.ConnConfig.Tracer = tr
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+
+	// The first acquire finds the pool empty and connects.
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Release()
+
+	// The second one reuses the idle connection.
+	conn, err = pool.Acquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Release()
+
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := pool.Acquire(canceledCtx); err == nil {
+		t.Fatal("Acquire() with a canceled context succeeded")
+	}
+
+	parent.End()
+
+	var acquires, releases []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		switch span.Name() {
+		case "pool.acquire":
+			acquires = append(acquires, span)
+		case "pool.release":
+			releases = append(releases, span)
+		}
+	}
+
+	if len(acquires) != 3 || len(releases) != 2 {
+		t.Fatalf("got %d acquire and %d release spans, want 3 and 2", len(acquires), len(releases))
+	}
+
+	for i, wantEmpty := range []bool{true, false, false} {
+		span := acquires[i]
+		attrs := attribute.NewSet(span.Attributes()...)
+
+		if span.SpanKind() != trace.SpanKindInternal {
+			t.Errorf("acquire %d: span kind = %v, want internal", i, span.SpanKind())
+		}
+		if got, _ := attrs.Value(PoolEmptyKey); got.AsBool() != wantEmpty {
+			t.Errorf("acquire %d: %v = %v, want %v", i, PoolEmptyKey, got.AsBool(), wantEmpty)
+		}
+		if got, ok := attrs.Value(PoolAcquireWaitKey); !ok || got.AsFloat64() < 0 {
+			t.Errorf("acquire %d: %v = %v, want a wait", i, PoolAcquireWaitKey, got.Emit())
+		}
+		if got, _ := attrs.Value(semconv.DBNameKey); got.AsString() != "orders" {
+			t.Errorf("acquire %d: %v = %q, want orders", i, semconv.DBNameKey, got.AsString())
+		}
+	}
+
+	canceled := acquires[2]
+	if canceled.Status().Code != codes.Error {
+		t.Errorf("canceled acquire: status = %v, want error", canceled.Status().Code)
+	}
+	canceledAttrs := attribute.NewSet(canceled.Attributes()...)
+	if got, _ := canceledAttrs.Value(ErrorCauseKey); got.AsString() != ErrorCauseCanceled {
+		t.Errorf("canceled acquire: %v = %q, want %q", ErrorCauseKey, got.AsString(), ErrorCauseCanceled)
+	}
+
+	for i, span := range releases {
+		if span.SpanKind() != trace.SpanKindInternal {
+			t.Errorf("release %d: span kind = %v, want internal", i, span.SpanKind())
+		}
+		if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("release %d: not a child of the caller span", i)
+		}
+		attrs := attribute.NewSet(span.Attributes()...)
+		if got, ok := attrs.Value(PoolConnHoldKey); !ok || got.AsFloat64() < 0 {
+			t.Errorf("release %d: %v = %v, want a hold duration", i, PoolConnHoldKey, got.Emit())
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package otelpgx
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+)
+
+// Keys of the stable database semantic conventions which are not part of the
+// semconv package version used by this package,
+// see https://opentelemetry.io/docs/specs/semconv/database/database-spans/.
+const (
+	DBSystemNameKey         = attribute.Key("db.system.name")
+	DBNamespaceKey          = attribute.Key("db.namespace")
+	DBQueryTextKey          = attribute.Key("db.query.text")
+	DBOperationNameKey      = attribute.Key("db.operation.name")
+	DBCollectionNameKey     = attribute.Key("db.collection.name")
+	DBResponseStatusCodeKey = attribute.Key("db.response.status_code")
+)
+
+// semConvStabilityOptInEnv is the environment variable used to opt in to the
+// stable semantic conventions.
+const semConvStabilityOptInEnv = "OTEL_SEMCONV_STABILITY_OPT_IN"
+
+// SemConvStability selects which database semantic conventions are emitted.
+// It mirrors the database values of the OTEL_SEMCONV_STABILITY_OPT_IN
+// environment variable.
+type SemConvStability int
+
+const (
+	// SemConvStabilityOld emits the experimental conventions used by
+	// semconv v1.25.0, such as db.statement and net.peer.name.
+	SemConvStabilityOld SemConvStability = iota
+	// SemConvStabilityNew emits the stable conventions only, such as
+	// db.query.text and server.address. It matches
+	// OTEL_SEMCONV_STABILITY_OPT_IN=database.
+	SemConvStabilityNew
+	// SemConvStabilityDup emits both the experimental and the stable
+	// conventions. It matches OTEL_SEMCONV_STABILITY_OPT_IN=database/dup.
+	SemConvStabilityDup
+)
+
+// semConvStabilityFromEnv returns the SemConvStability selected by the
+// OTEL_SEMCONV_STABILITY_OPT_IN environment variable.
+func semConvStabilityFromEnv() SemConvStability {
+	stability := SemConvStabilityOld
+	for _, v := range strings.Split(os.Getenv(semConvStabilityOptInEnv), ",") {
+		switch strings.TrimSpace(v) {
+		case "database/dup":
+			// database/dup takes precedence over database.
+			return SemConvStabilityDup
+		case "database":
+			stability = SemConvStabilityNew
+		}
+	}
+	return stability
+}
+
+func (s SemConvStability) emitOld() bool {
+	return s != SemConvStabilityNew
+}
+
+func (s SemConvStability) emitNew() bool {
+	return s != SemConvStabilityOld
+}
+
+// systemAttributes returns the attributes identifying PostgreSQL.
+func (s SemConvStability) systemAttributes() []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if s.emitOld() {
+		attrs = append(attrs, semconv.DBSystemPostgreSQL)
+	}
+	if s.emitNew() {
+		attrs = append(attrs, DBSystemNameKey.String(semconv.DBSystemPostgreSQL.Value.AsString()))
+	}
+	return attrs
+}
+
+// serverAttributes returns the attributes describing the server connected to.
+func (s SemConvStability) serverAttributes(config *pgx.ConnConfig) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if s.emitOld() {
+		attrs = append(attrs,
+			semconv.NetPeerName(config.Host),
+			semconv.NetPeerPort(int(config.Port)),
+		)
+	}
+	if s.emitNew() {
+		attrs = append(attrs,
+			semconv.ServerAddress(config.Host),
+			semconv.ServerPort(int(config.Port)),
+		)
+	}
+	return attrs
+}
+
+// userAttributes returns the attributes describing the connection user.
+func (s SemConvStability) userAttributes(config *pgx.ConnConfig) []attribute.KeyValue {
+	if s.emitOld() {
+		return []attribute.KeyValue{semconv.DBUser(config.User)}
+	}
+	return nil
+}
+
+// databaseAttributes returns the attributes describing the database
+// connected to.
+func (s SemConvStability) databaseAttributes(config *pgx.ConnConfig) []attribute.KeyValue {
+	if config.Database == "" {
+		return nil
+	}
+
+	var attrs []attribute.KeyValue
+	if s.emitOld() {
+		attrs = append(attrs, semconv.DBName(config.Database))
+	}
+	if s.emitNew() {
+		attrs = append(attrs, DBNamespaceKey.String(config.Database))
+	}
+	return attrs
+}
+
+// statementAttributes returns the attributes holding the statement text.
+func (s SemConvStability) statementAttributes(stmt string) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if s.emitOld() {
+		attrs = append(attrs, semconv.DBStatement(stmt))
+	}
+	if s.emitNew() {
+		attrs = append(attrs, DBQueryTextKey.String(stmt))
+	}
+	return attrs
+}
+
+// operationAttributes returns the attributes holding the operation name.
+func (s SemConvStability) operationAttributes(operation string) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if s.emitOld() {
+		attrs = append(attrs, semconv.DBOperation(operation))
+	}
+	if s.emitNew() {
+		attrs = append(attrs, DBOperationNameKey.String(operation))
+	}
+	return attrs
+}
+
+// tableAttributes returns the attributes holding the table name.
+func (s SemConvStability) tableAttributes(table string) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if s.emitOld() {
+		attrs = append(attrs, semconv.DBSQLTable(table))
+	}
+	if s.emitNew() {
+		attrs = append(attrs, DBCollectionNameKey.String(table))
+	}
+	return attrs
+}
+
+// responseStatusAttributes returns the attributes holding the SQLSTATE of
+// err, if it is a PostgreSQL error.
+func (s SemConvStability) responseStatusAttributes(err error) []attribute.KeyValue {
+	if !s.emitNew() {
+		return nil
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return []attribute.KeyValue{DBResponseStatusCodeKey.String(pgErr.Code)}
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package otelpgx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+)
+
+func TestSemConvStabilityFromEnv(t *testing.T) {
+	tests := []struct {
+		env  string
+		want SemConvStability
+	}{
+		{env: "", want: SemConvStabilityOld},
+		{env: "http", want: SemConvStabilityOld},
+		{env: "database", want: SemConvStabilityNew},
+		{env: "http, database", want: SemConvStabilityNew},
+		{env: "database/dup", want: SemConvStabilityDup},
+		{env: "database,database/dup", want: SemConvStabilityDup},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			t.Setenv(semConvStabilityOptInEnv, tt.env)
+			if got := semConvStabilityFromEnv(); got != tt.want {
+				t.Errorf("semConvStabilityFromEnv() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTracer_semConvStability(t *testing.T) {
+	tests := []struct {
+		name      string
+		stability SemConvStability
+		wantKeys  []attribute.Key
+		wantNoKey []attribute.Key
+	}{
+		{
+			name:      "Old",
+			stability: SemConvStabilityOld,
+			wantKeys:  []attribute.Key{semconv.DBSystemKey, semconv.DBStatementKey, semconv.ErrorTypeKey},
+			wantNoKey: []attribute.Key{DBSystemNameKey, DBQueryTextKey, DBResponseStatusCodeKey},
+		},
+		{
+			name:      "New",
+			stability: SemConvStabilityNew,
+			wantKeys:  []attribute.Key{DBSystemNameKey, DBQueryTextKey, semconv.ErrorTypeKey, DBResponseStatusCodeKey},
+			wantNoKey: []attribute.Key{semconv.DBSystemKey, semconv.DBStatementKey},
+		},
+		{
+			name:      "Dup",
+			stability: SemConvStabilityDup,
+			wantKeys: []attribute.Key{
+				semconv.DBSystemKey, semconv.DBStatementKey,
+				DBSystemNameKey, DBQueryTextKey, semconv.ErrorTypeKey, DBResponseStatusCodeKey,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			tr := NewTracer(WithTracerProvider(tp), WithSemConvStability(tt.stability))
+
+			ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+			ctx = tr.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "SELECT 1"})
+			tr.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: &pgconn.PgError{Code: "42P01"}})
+			parent.End()
+
+			spans := recorder.Ended()
+			if len(spans) != 2 {
+				t.Fatalf("got %d ended spans, want 2", len(spans))
+			}
+
+			attrs := attribute.NewSet(spans[0].Attributes()...)
+			for _, key := range tt.wantKeys {
+				if !attrs.HasValue(key) {
+					t.Errorf("missing attribute %v", key)
+				}
+			}
+			for _, key := range tt.wantNoKey {
+				if attrs.HasValue(key) {
+					t.Errorf("unexpected attribute %v", key)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package otelpgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/piusalfred/otelpgx/internal/sqltoken"
+)
+
+// testServer is a minimal PostgreSQL server answering every statement with
+// an empty result, for the tests needing live connections.
+type testServer struct {
+	listener net.Listener
+
+	mu         sync.Mutex
+	statements []string
+}
+
+// newTestServer starts a testServer, stopped when the test ends.
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &testServer{listener: listener}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+// connConfig returns the config of a connection to the server.
+func (s *testServer) connConfig(t *testing.T) *pgx.ConnConfig {
+	t.Helper()
+
+	config, err := pgx.ParseConfig(fmt.Sprintf("postgres://app@%s/orders?sslmode=disable", s.listener.Addr()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return config
+}
+
+// connect returns a connection to the server, closed when the test ends.
+func (s *testServer) connect(t *testing.T, config *pgx.ConnConfig) *pgx.Conn {
+	t.Helper()
+
+	conn, err := pgx.ConnectConfig(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close(context.Background()) })
+
+	return conn
+}
+
+// received returns the statements received by the server, in order.
+func (s *testServer) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.statements...)
+}
+
+func (s *testServer) record(sql string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statements = append(s.statements, sql)
+}
+
+func (s *testServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	backend := pgproto3.NewBackend(conn, conn)
+	startup, err := backend.ReceiveStartupMessage()
+	if err != nil {
+		return
+	}
+
+	var user string
+	if msg, ok := startup.(*pgproto3.StartupMessage); ok {
+		user = msg.Parameters["user"]
+	}
+
+	backend.Send(&pgproto3.AuthenticationOk{})
+	backend.Send(&pgproto3.ParameterStatus{Name: "server_version", Value: "16.0"})
+	backend.Send(&pgproto3.ParameterStatus{Name: "session_authorization", Value: user})
+	backend.Send(&pgproto3.ParameterStatus{Name: "standard_conforming_strings", Value: "on"})
+	backend.Send(&pgproto3.BackendKeyData{ProcessID: 4242, SecretKey: 1})
+	backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+	if err := backend.Flush(); err != nil {
+		return
+	}
+
+	statements := make(map[string]string)
+	var portal string
+	txStatus := byte('I')
+
+	for {
+		msg, err := backend.Receive()
+		if err != nil {
+			return
+		}
+
+		switch msg := msg.(type) {
+		case *pgproto3.Query:
+			s.record(msg.String)
+			for _, stmt := range strings.Split(msg.String, ";") {
+				if strings.TrimSpace(stmt) != "" {
+					txStatus = nextTxStatus(txStatus, stmt)
+					if isCopyTo(stmt) {
+						backend.Send(&pgproto3.CopyOutResponse{})
+						backend.Send(&pgproto3.CopyData{Data: []byte("1\tada\n")})
+						backend.Send(&pgproto3.CopyDone{})
+						backend.Send(&pgproto3.CommandComplete{CommandTag: []byte("COPY 1")})
+						continue
+					}
+					backend.Send(&pgproto3.CommandComplete{CommandTag: commandTag(stmt)})
+				}
+			}
+			backend.Send(&pgproto3.ReadyForQuery{TxStatus: txStatus})
+		case *pgproto3.Parse:
+			s.record(msg.Query)
+			statements[msg.Name] = msg.Query
+			backend.Send(&pgproto3.ParseComplete{})
+		case *pgproto3.Describe:
+			if msg.ObjectType == 'S' {
+				oids := make([]uint32, countParams(statements[msg.Name]))
+				for i := range oids {
+					oids[i] = 25 // text
+				}
+				backend.Send(&pgproto3.ParameterDescription{ParameterOIDs: oids})
+			}
+			backend.Send(&pgproto3.NoData{})
+		case *pgproto3.Bind:
+			portal = statements[msg.PreparedStatement]
+			backend.Send(&pgproto3.BindComplete{})
+		case *pgproto3.Execute:
+			txStatus = nextTxStatus(txStatus, portal)
+			backend.Send(&pgproto3.CommandComplete{CommandTag: commandTag(portal)})
+		case *pgproto3.Close:
+			backend.Send(&pgproto3.CloseComplete{})
+		case *pgproto3.Sync:
+			backend.Send(&pgproto3.ReadyForQuery{TxStatus: txStatus})
+		case *pgproto3.Terminate:
+			return
+		}
+
+		if err := backend.Flush(); err != nil && !errors.Is(err, net.ErrClosed) {
+			return
+		}
+	}
+}
+
+// nextTxStatus returns the transaction status of a session in status after
+// sql ran.
+func nextTxStatus(status byte, sql string) byte {
+	fields := strings.Fields(strings.ToUpper(sql))
+	if len(fields) == 0 {
+		return status
+	}
+
+	switch fields[0] {
+	case "BEGIN", "START":
+		return 'T'
+	case "COMMIT", "END":
+		return 'I'
+	case "ROLLBACK", "ABORT":
+		if len(fields) > 1 && fields[1] == "TO" {
+			return status
+		}
+		return 'I'
+	default:
+		return status
+	}
+}
+
+// isCopyTo reports whether sql is a COPY ... TO STDOUT statement, answered
+// with a single row.
+func isCopyTo(sql string) bool {
+	sql = strings.ToUpper(sql)
+	return strings.HasPrefix(strings.TrimSpace(sql), "COPY") && strings.Contains(sql, "TO STDOUT")
+}
+
+// commandTag returns the command tag of a statement affecting no rows.
+func commandTag(sql string) []byte {
+	fields := strings.Fields(sql)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	switch op := strings.ToUpper(fields[0]); op {
+	case "SELECT", "UPDATE", "DELETE":
+		return []byte(op + " 0")
+	case "INSERT":
+		return []byte("INSERT 0 0")
+	default:
+		return []byte(op)
+	}
+}
+
+// countParams returns the number of parameters of sql, such as 2 for
+// SELECT $1, $2.
+func countParams(sql string) int {
+	n := 0
+	for _, tok := range sqltoken.Tokenize(sql) {
+		var i int
+		if tok.Kind == sqltoken.Param {
+			if _, err := fmt.Sscanf(tok.Text, "$%d", &i); err == nil && i > n {
+				n = i
+			}
+		}
+	}
+	return n
+}
@@ -0,0 +1,60 @@
+package otelpgx
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/tracelog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// SlowQueryKey represents whether the operation took longer than the
+	// slow query threshold.
+	SlowQueryKey = attribute.Key("pgx.slow_query")
+	// SlowQueryThresholdKey represents the slow query threshold in
+	// milliseconds.
+	SlowQueryThresholdKey = attribute.Key("pgx.slow_query.threshold_ms")
+	// DurationKey represents the duration of the operation in milliseconds.
+	DurationKey = attribute.Key("pgx.duration_ms")
+)
+
+// slowQueryEvent is the name of the span event added to slow operations.
+const slowQueryEvent = "slow_query"
+
+// detectSlowQuery annotates span, logs the statement and increments the slow
+// query counter when the operation op took longer than the slow query
+// threshold. The timing does not depend on the span being recorded.
+func (t *Tracer) detectSlowQuery(ctx context.Context, span trace.Span, op operationStart, elapsed time.Duration, err error) {
+	if t.slowQueryThreshold <= 0 || elapsed < t.slowQueryThreshold {
+		return
+	}
+
+	span.SetAttributes(SlowQueryKey.Bool(true))
+	span.AddEvent(slowQueryEvent, trace.WithAttributes(
+		DurationKey.Float64(milliseconds(elapsed)),
+		SlowQueryThresholdKey.Float64(milliseconds(t.slowQueryThreshold)),
+	))
+
+	if t.slowQueries != nil {
+		t.slowQueries.Add(ctx, 1, metric.WithAttributes(t.operationMetricAttributes(ctx, op, err)...))
+	}
+
+	if l := t.slowQueryLogger; l != nil && l.converter.ToTraceLogLevel(l.level) >= tracelog.LogLevelWarn {
+		data := map[string]any{
+			"operation": op.name,
+			"time":      elapsed,
+			"threshold": t.slowQueryThreshold,
+		}
+		if op.statement != "" {
+			data["sql"] = t.statement(op.statement)
+		}
+		if err != nil {
+			data["err"] = err
+		}
+
+		l.Log(ctx, tracelog.LogLevelWarn, "slow query", data)
+	}
+}
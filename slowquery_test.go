@@ -0,0 +1,80 @@
+package otelpgx
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracer_slowQuery(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn}))
+
+	reader := sdkmetric.NewManualReader()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	tr := NewTracer(
+		WithTracerProvider(tp),
+		WithTracerMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+		WithSlowQueryThreshold(1),
+		WithSlowQueryLogger(WithLogger(logger)),
+		WithSQLObfuscation(),
+	)
+
+	// Not sampled, the slow query must still be logged and counted.
+	ctx := tr.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "SELECT * FROM users WHERE email = 'a@b.c'"})
+	tr.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	ctx = tr.TraceBatchStart(ctx, nil, pgx.TraceBatchStartData{})
+	tr.TraceBatchEnd(ctx, nil, pgx.TraceBatchEndData{})
+	parent.End()
+
+	if got := strings.Count(buf.String(), "slow query"); got != 2 {
+		t.Errorf("logged %d slow queries, want 2:\n%s", got, buf.String())
+	}
+	if strings.Contains(buf.String(), "a@b.c") {
+		t.Errorf("the logged statement was not obfuscated:\n%s", buf.String())
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d ended spans, want 2", len(spans))
+	}
+	batch := spans[0]
+	attrs := attribute.NewSet(batch.Attributes()...)
+	if v, _ := attrs.Value(SlowQueryKey); !v.AsBool() {
+		t.Errorf("missing %v attribute", SlowQueryKey)
+	}
+	if len(batch.Events()) != 1 || batch.Events()[0].Name != slowQueryEvent {
+		t.Errorf("unexpected events %v", batch.Events())
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+
+	var count int64
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		if m.Name != slowQueriesName {
+			continue
+		}
+		for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+			count += dp.Value
+		}
+	}
+	if count != 2 {
+		t.Errorf("slow query count = %d, want 2", count)
+	}
+}
@@ -0,0 +1,235 @@
+package otelpgx
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/piusalfred/otelpgx/internal/sqltoken"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Querier runs queries. It is implemented by *pgx.Conn, *pgxpool.Pool,
+// *pgxpool.Conn and pgx.Tx.
+type Querier interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+type sqlCommentTagsKey struct{}
+
+// ContextWithSQLCommentTags returns a copy of ctx carrying tags, such as
+// route, controller or application, which are added to the sqlcommenter
+// comments of the statements run with it, see WithSQLCommenter. The tags are
+// merged with the ones already carried by ctx.
+func ContextWithSQLCommentTags(ctx context.Context, tags map[string]string) context.Context {
+	merged := make(map[string]string)
+	for k, v := range sqlCommentTagsFromContext(ctx) {
+		merged[k] = v
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, sqlCommentTagsKey{}, merged)
+}
+
+func sqlCommentTagsFromContext(ctx context.Context) map[string]string {
+	tags, _ := ctx.Value(sqlCommentTagsKey{}).(map[string]string)
+	return tags
+}
+
+// Querier returns db wrapped so that the statements it runs get a
+// sqlcommenter comment carrying the traceparent of the query span and the
+// tags set with ContextWithSQLCommentTags, see https://google.github.io/sqlcommenter/spec/.
+// It returns db as is unless WithSQLCommenter is used.
+//
+// The comment is appended by a pgx.QueryRewriter, after the Tracer saw the
+// statement of queries, so their span names and attributes are not affected.
+// pgx rewrites the queries of batches, and the statements it prepares on
+// behalf of queries, before tracing them: the Tracer strips the comment from
+// those instead. An existing rewriter such as pgx.NamedArgs is still applied.
+//
+// The traceparent is omitted for statements run with the
+// QueryExecModeCacheStatement or QueryExecModeCacheDescribe modes, which
+// are the defaults, as a statement differing on every call would defeat the
+// statement caches. The other tags are expected to have few distinct values.
+func (t *Tracer) Querier(db Querier) Querier {
+	if !t.sqlCommenter {
+		return db
+	}
+	return &commentingQuerier{db: db}
+}
+
+// commentingQuerier is a Querier adding sqlcommenter comments to statements.
+type commentingQuerier struct {
+	db Querier
+}
+
+func (q *commentingQuerier) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	return q.db.Exec(ctx, sql, withSQLCommentRewriter(arguments)...)
+}
+
+func (q *commentingQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return q.db.Query(ctx, sql, withSQLCommentRewriter(args)...)
+}
+
+func (q *commentingQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return q.db.QueryRow(ctx, sql, withSQLCommentRewriter(args)...)
+}
+
+func (q *commentingQuerier) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	commented := &pgx.Batch{QueuedQueries: make([]*pgx.QueuedQuery, len(b.QueuedQueries))}
+	for i, qq := range b.QueuedQueries {
+		c := *qq
+		c.Arguments = withSQLCommentRewriter(qq.Arguments)
+		commented.QueuedQueries[i] = &c
+	}
+
+	return q.db.SendBatch(ctx, commented)
+}
+
+// withSQLCommentRewriter returns args with a sqlCommentRewriter inserted after
+// the leading query options, wrapping the query rewriter found among them.
+func withSQLCommentRewriter(args []any) []any {
+	rewriter := sqlCommentRewriter{}
+
+	out := make([]any, 0, len(args)+1)
+
+	i := 0
+options:
+	for ; i < len(args); i++ {
+		switch arg := args[i].(type) {
+		case pgx.QueryExecMode:
+			rewriter.mode = arg
+			rewriter.explicitMode = true
+		case pgx.QueryRewriter:
+			rewriter.inner = arg
+			continue
+		case pgx.QueryResultFormats, pgx.QueryResultFormatsByOID:
+		default:
+			break options
+		}
+		out = append(out, args[i])
+	}
+
+	out = append(out, rewriter)
+	return append(out, args[i:]...)
+}
+
+// sqlCommentRewriter is a pgx.QueryRewriter appending a sqlcommenter comment
+// to statements.
+type sqlCommentRewriter struct {
+	inner        pgx.QueryRewriter
+	mode         pgx.QueryExecMode
+	explicitMode bool
+}
+
+func (r sqlCommentRewriter) RewriteQuery(ctx context.Context, conn *pgx.Conn, sql string, args []any) (string, []any, error) {
+	if r.inner != nil {
+		var err error
+		sql, args, err = r.inner.RewriteQuery(ctx, conn, sql, args)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	mode := r.mode
+	if !r.explicitMode && conn != nil {
+		mode = connDefaultQueryExecMode(conn)
+	}
+
+	return appendSQLComment(ctx, sql, !cachesStatements(mode)), args, nil
+}
+
+// appendSQLComment returns sql with a sqlcommenter comment inserted after its
+// last token, before any trailing semicolon, whitespace or comment.
+// Statements without whitespace, which may be prepared statement names, are
+// returned as is.
+func appendSQLComment(ctx context.Context, sql string, withTraceparent bool) string {
+	if !strings.ContainsAny(sql, " \t\n\r\f\v") {
+		return sql
+	}
+
+	tags := make(map[string]string)
+	for k, v := range sqlCommentTagsFromContext(ctx) {
+		tags[k] = v
+	}
+	if withTraceparent {
+		propagation.TraceContext{}.Inject(ctx, propagation.MapCarrier(tags))
+	}
+	if len(tags) == 0 {
+		return sql
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = sqlCommentEscape(k) + "='" + sqlCommentEscape(tags[k]) + "'"
+	}
+	comment := " /*" + strings.Join(pairs, ",") + "*/"
+
+	// Find the end of the last token which is not part of the trailer.
+	end := 0
+	pos := 0
+	for _, tok := range sqltoken.Tokenize(sql) {
+		pos += len(tok.Text)
+		if tok.Kind != sqltoken.Whitespace && tok.Kind != sqltoken.Comment && tok.Text != ";" {
+			end = pos
+		}
+	}
+
+	return sql[:end] + comment + sql[end:]
+}
+
+// sqlCommentPattern matches the comments inserted by appendSQLComment. As
+// keys and values are URL-encoded, they never contain */.
+var sqlCommentPattern = regexp.MustCompile(`^/\*[^=,'/*]+='(?:[^'\\]|\\.)*'(?:,[^=,'/*]+='(?:[^'\\]|\\.)*')*\*/$`)
+
+// stripSQLComment returns sql without the sqlcommenter comment inserted by
+// appendSQLComment, if any.
+func stripSQLComment(sql string) string {
+	if !strings.Contains(sql, "*/") {
+		return sql
+	}
+
+	tokens := sqltoken.Tokenize(sql)
+
+	// Find the last token which is not part of the trailer, which the
+	// comment follows.
+	last, end := -1, 0
+	pos := 0
+	for i, tok := range tokens {
+		pos += len(tok.Text)
+		if tok.Kind != sqltoken.Whitespace && tok.Kind != sqltoken.Comment && tok.Text != ";" {
+			last, end = i, pos
+		}
+	}
+
+	if last < 0 || last+2 >= len(tokens) {
+		return sql
+	}
+
+	space, comment := tokens[last+1], tokens[last+2]
+	if space.Text != " " || comment.Kind != sqltoken.Comment || !sqlCommentPattern.MatchString(comment.Text) {
+		return sql
+	}
+
+	return sql[:end] + sql[end+len(space.Text)+len(comment.Text):]
+}
+
+// sqlCommentEscape URL-encodes s and escapes the quotes left by the encoding,
+// as required by the sqlcommenter specification.
+func sqlCommentEscape(s string) string {
+	return strings.ReplaceAll(url.PathEscape(s), "'", `\'`)
+}
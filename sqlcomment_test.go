@@ -0,0 +1,227 @@
+package otelpgx
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestAppendSQLComment(t *testing.T) {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+	ctx = ContextWithSQLCommentTags(ctx, map[string]string{"route": "/users/{id}"})
+	ctx = ContextWithSQLCommentTags(ctx, map[string]string{"application": "it's"})
+
+	const traceparent = "traceparent='00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01'"
+
+	tests := []struct {
+		name            string
+		sql             string
+		withTraceparent bool
+		want            string
+	}{
+		{
+			name:            "With traceparent",
+			sql:             "SELECT * FROM users WHERE id = $1",
+			withTraceparent: true,
+			want:            "SELECT * FROM users WHERE id = $1 /*application='it%27s',route='%2Fusers%2F%7Bid%7D'," + traceparent + "*/",
+		},
+		{
+			name: "Without traceparent",
+			sql:  "SELECT 1",
+			want: "SELECT 1 /*application='it%27s',route='%2Fusers%2F%7Bid%7D'*/",
+		},
+		{
+			name: "Trailing semicolon and comment",
+			sql:  "SELECT 1; -- done\n",
+			want: "SELECT 1 /*application='it%27s',route='%2Fusers%2F%7Bid%7D'*/; -- done\n",
+		},
+		{
+			name: "Prepared statement name",
+			sql:  "get_user",
+			want: "get_user",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := appendSQLComment(ctx, tt.sql, tt.withTraceparent); got != tt.want {
+				t.Errorf("appendSQLComment() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithSQLCommentRewriter(t *testing.T) {
+	named := pgx.NamedArgs{"id": 1}
+	args := withSQLCommentRewriter([]any{pgx.QueryExecModeExec, named})
+
+	if len(args) != 2 || args[0] != pgx.QueryExecModeExec {
+		t.Fatalf("unexpected arguments %v", args)
+	}
+
+	r, ok := args[1].(sqlCommentRewriter)
+	if !ok {
+		t.Fatalf("unexpected argument %T, want sqlCommentRewriter", args[1])
+	}
+	if r.mode != pgx.QueryExecModeExec || !r.explicitMode {
+		t.Errorf("exec mode = %v, want %v", r.mode, pgx.QueryExecModeExec)
+	}
+
+	sql, rewritten, err := r.RewriteQuery(context.Background(), nil, "SELECT @id", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sql != "SELECT $1" || len(rewritten) != 1 || rewritten[0] != 1 {
+		t.Errorf("RewriteQuery() = %q, %v, want the named arguments rewritten", sql, rewritten)
+	}
+}
+
+func TestStripSQLComment(t *testing.T) {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x01},
+		SpanID:     trace.SpanID{0x02},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+	ctx = ContextWithSQLCommentTags(ctx, map[string]string{"route": "/users/{id}", "application": "it's"})
+
+	tests := []struct {
+		name string
+		sql  string
+		want string
+	}{
+		{
+			name: "Appended comment",
+			sql:  appendSQLComment(ctx, "SELECT * FROM users WHERE id = $1", true),
+			want: "SELECT * FROM users WHERE id = $1",
+		},
+		{
+			name: "Trailing semicolon and comment",
+			sql:  appendSQLComment(ctx, "SELECT 1; -- done\n", false),
+			want: "SELECT 1; -- done\n",
+		},
+		{
+			name: "No comment",
+			sql:  "SELECT 1",
+			want: "SELECT 1",
+		},
+		{
+			name: "Other comment",
+			sql:  "SELECT 1 /* not a tag */",
+			want: "SELECT 1 /* not a tag */",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stripSQLComment(tt.sql); got != tt.want {
+				t.Errorf("stripSQLComment(%q) = %q, want %q", tt.sql, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTracer_QuerierBatch(t *testing.T) {
+	server := newTestServer(t)
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tr := NewTracer(WithTracerProvider(tp), WithSQLCommenter(), WithSemConvStability(SemConvStabilityOld))
+
+	config := server.connConfig(t)
+	config.Tracer = tr
+	config.DefaultQueryExecMode = pgx.QueryExecModeExec
+	conn := server.connect(t, config)
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+
+	const sql = "SELECT * FROM users WHERE id = $1"
+
+	b := &pgx.Batch{}
+	b.Queue(sql, "1")
+	if err := tr.Querier(conn).SendBatch(ctx, b).Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	parent.End()
+
+	var commented bool
+	for _, stmt := range server.received() {
+		commented = commented || strings.Contains(stmt, "traceparent=")
+	}
+	if !commented {
+		t.Errorf("no statement received with a traceparent: %q", server.received())
+	}
+
+	var found bool
+	for _, span := range recorder.Ended() {
+		if !strings.HasPrefix(span.Name(), "batch query") {
+			continue
+		}
+		found = true
+
+		if want := "batch query " + sql; span.Name() != want {
+			t.Errorf("span name = %q, want %q", span.Name(), want)
+		}
+		attrs := attribute.NewSet(span.Attributes()...)
+		if got, _ := attrs.Value(semconv.DBStatementKey); got.AsString() != sql {
+			t.Errorf("%v = %q, want %q", semconv.DBStatementKey, got.AsString(), sql)
+		}
+	}
+	if !found {
+		t.Error("no batch query span")
+	}
+}
+
+func TestTracer_QuerierRedactParams(t *testing.T) {
+	server := newTestServer(t)
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tr := NewTracer(
+		WithTracerProvider(tp),
+		WithSQLCommenter(),
+		WithIncludeQueryParameters(),
+		WithQueryParameterRedactor(RedactParams("$1")),
+	)
+
+	config := server.connConfig(t)
+	config.Tracer = tr
+	conn := server.connect(t, config)
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	if _, err := tr.Querier(conn).Exec(ctx, "UPDATE users SET password = $1 WHERE name = $2", "secret", "ada"); err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+
+	var found bool
+	for _, span := range recorder.Ended() {
+		attrs := attribute.NewSet(span.Attributes()...)
+		params, ok := attrs.Value(QueryParametersKey)
+		if !ok {
+			continue
+		}
+		found = true
+
+		if got, want := params.AsStringSlice(), []string{redactedParam, "ada"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("%v = %q, want %q", QueryParametersKey, got, want)
+		}
+	}
+	if !found {
+		t.Errorf("no span with %v", QueryParametersKey)
+	}
+}
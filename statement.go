@@ -0,0 +1,154 @@
+package otelpgx
+
+import (
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/piusalfred/otelpgx/internal/sqltoken"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// parseStatement returns the operation of the SELECT, INSERT, UPDATE, DELETE
+// or MERGE statement sql and the first table it operates on. Leading common
+// table expressions are skipped. The operation is empty if sql is not one of
+// these statements, and the table is empty if it could not be found.
+func parseStatement(sql string) (operation, table string) {
+	tokens := significantTokens(sqltoken.Tokenize(sql))
+	if len(tokens) == 0 {
+		return "", ""
+	}
+
+	i := 0
+	if tokens[0].IsKeyword("WITH") {
+		// The main statement is the first one outside the parentheses of
+		// the common table expressions.
+		i = len(tokens)
+	cte:
+		for j, depth := 1, 0; j < len(tokens); j++ {
+			switch {
+			case tokens[j].Text == "(":
+				depth++
+			case tokens[j].Text == ")":
+				depth--
+			case depth == 0 && isDMLKeyword(tokens[j]):
+				i = j
+				break cte
+			}
+		}
+		if i == len(tokens) {
+			return "", ""
+		}
+	}
+
+	if !isDMLKeyword(tokens[i]) {
+		return "", ""
+	}
+
+	operation = strings.ToUpper(tokens[i].Text)
+	rest := tokens[i+1:]
+
+	switch operation {
+	case "SELECT":
+		// The first FROM at the top level, which is neither part of a
+		// function call such as extract(year FROM d) nor of a subquery.
+		for j, depth := 0, 0; j < len(rest); j++ {
+			switch {
+			case rest[j].Text == "(":
+				depth++
+			case rest[j].Text == ")":
+				depth--
+			case depth == 0 && rest[j].IsKeyword("FROM"):
+				return operation, tableName(rest[j+1:])
+			}
+		}
+		return operation, ""
+	case "INSERT", "MERGE":
+		rest = skipKeyword(rest, "INTO")
+	case "DELETE":
+		rest = skipKeyword(rest, "FROM")
+	}
+
+	return operation, tableName(skipKeyword(rest, "ONLY"))
+}
+
+// significantTokens returns tokens without whitespace and comments.
+func significantTokens(tokens []sqltoken.Token) []sqltoken.Token {
+	out := tokens[:0:0]
+	for _, tok := range tokens {
+		if tok.Kind != sqltoken.Whitespace && tok.Kind != sqltoken.Comment {
+			out = append(out, tok)
+		}
+	}
+	return out
+}
+
+func isDMLKeyword(tok sqltoken.Token) bool {
+	for _, kw := range []string{"SELECT", "INSERT", "UPDATE", "DELETE", "MERGE"} {
+		if tok.IsKeyword(kw) {
+			return true
+		}
+	}
+	return false
+}
+
+func skipKeyword(tokens []sqltoken.Token, kw string) []sqltoken.Token {
+	if len(tokens) > 0 && tokens[0].IsKeyword(kw) {
+		return tokens[1:]
+	}
+	return tokens
+}
+
+// tableName returns the, possibly schema-qualified, table name at the start
+// of tokens as written in the statement.
+func tableName(tokens []sqltoken.Token) string {
+	tokens = skipKeyword(tokens, "ONLY")
+
+	var b strings.Builder
+	for i, tok := range tokens {
+		wantName := i%2 == 0
+		switch {
+		case wantName && (tok.Kind == sqltoken.Ident || tok.Kind == sqltoken.QuotedIdent):
+			b.WriteString(tok.Text)
+		case !wantName && tok.Text == ".":
+			b.WriteString(tok.Text)
+		case wantName:
+			// Not a name, for example the parenthesis of a subquery or a
+			// dangling dot.
+			return strings.TrimSuffix(b.String(), ".")
+		default:
+			return b.String()
+		}
+	}
+	return strings.TrimSuffix(b.String(), ".")
+}
+
+// operationTableSpanName returns the "{db.operation} {db.name}.{db.sql.table}"
+// span name of sql and the matching span attributes. The returned name is
+// empty if sql is not a recognized statement.
+func (t *Tracer) operationTableSpanName(conn *pgx.Conn, sql string) (string, []attribute.KeyValue) {
+	operation, table := parseStatement(sql)
+	if operation == "" {
+		return "", nil
+	}
+
+	attrs := t.semConvStability.operationAttributes(operation)
+
+	var target string
+	if conn != nil {
+		target = conn.Config().Database
+	}
+
+	if table != "" {
+		attrs = append(attrs, t.semConvStability.tableAttributes(table)...)
+		if target != "" {
+			target += "."
+		}
+		target += table
+	}
+
+	if target == "" {
+		return operation, attrs
+	}
+
+	return operation + " " + target, attrs
+}
@@ -0,0 +1,82 @@
+package otelpgx
+
+import "testing"
+
+func TestParseStatement(t *testing.T) {
+	tests := []struct {
+		name     string
+		stmt     string
+		expOp    string
+		expTable string
+	}{
+		{
+			name:     "Select",
+			stmt:     "SELECT id, extract(year FROM created_at) FROM app.users WHERE id = $1",
+			expOp:    "SELECT",
+			expTable: "app.users",
+		},
+		{
+			name:     "Select with comment",
+			stmt:     "-- name: GetUser :one\nselect * from \"Users\" u",
+			expOp:    "SELECT",
+			expTable: `"Users"`,
+		},
+		{
+			name:  "Select from subquery",
+			stmt:  "SELECT * FROM (SELECT 1) AS t",
+			expOp: "SELECT",
+		},
+		{
+			name:  "Select without table",
+			stmt:  "SELECT 1",
+			expOp: "SELECT",
+		},
+		{
+			name:     "Insert on conflict",
+			stmt:     "INSERT INTO users (id, name) VALUES ($1, $2) ON CONFLICT (id) DO UPDATE SET name = excluded.name",
+			expOp:    "INSERT",
+			expTable: "users",
+		},
+		{
+			name:     "Update only",
+			stmt:     "UPDATE ONLY public.users SET name = $1",
+			expOp:    "UPDATE",
+			expTable: "public.users",
+		},
+		{
+			name:     "Delete",
+			stmt:     "DELETE FROM users WHERE id = $1",
+			expOp:    "DELETE",
+			expTable: "users",
+		},
+		{
+			name:     "Merge",
+			stmt:     "MERGE INTO accounts a USING updates u ON a.id = u.id WHEN MATCHED THEN UPDATE SET balance = u.balance",
+			expOp:    "MERGE",
+			expTable: "accounts",
+		},
+		{
+			name:     "CTE",
+			stmt:     "WITH RECURSIVE t(n) AS (SELECT 1 FROM seed UNION ALL SELECT n + 1 FROM t), d AS (DELETE FROM old RETURNING *) INSERT INTO archive SELECT * FROM d",
+			expOp:    "INSERT",
+			expTable: "archive",
+		},
+		{
+			name: "Other statement",
+			stmt: "BEGIN",
+		},
+		{
+			name: "Empty statement",
+			stmt: " ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op, table := parseStatement(tt.stmt)
+			if op != tt.expOp || table != tt.expTable {
+				t.Errorf("parseStatement() = %q, %q, want %q, %q", op, table, tt.expOp, tt.expTable)
+			}
+		})
+	}
+}
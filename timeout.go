@@ -0,0 +1,391 @@
+package otelpgx
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/piusalfred/otelpgx/internal/sqltoken"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// ErrorCauseKey represents why an operation was interrupted, see the
+	// ErrorCause constants.
+	ErrorCauseKey = attribute.Key("pgx.error.cause")
+	// DeadlineRemainingKey represents the time left before the deadline of
+	// the context of the operation when it started, in milliseconds.
+	DeadlineRemainingKey = attribute.Key("pgx.deadline_remaining_ms")
+	// StatementTimeoutKey represents the statement_timeout of the session in
+	// milliseconds, 0 meaning no timeout. It is best-effort, see
+	// sessionTimeouts.
+	StatementTimeoutKey = attribute.Key("pgx.statement_timeout_ms")
+	// LockTimeoutKey represents the lock_timeout of the session in
+	// milliseconds, 0 meaning no timeout. It is best-effort, see
+	// sessionTimeouts.
+	LockTimeoutKey = attribute.Key("pgx.lock_timeout_ms")
+)
+
+// Values of the pgx.error.cause attribute. PostgreSQL reports statement
+// timeouts, lock timeouts and other cancellations with the same SQLSTATE
+// codes, so they are told apart by their message, which only works with the
+// English lc_messages: with other languages, statement timeouts are reported
+// as ErrorCauseQueryCanceled, and lock timeouts are not reported.
+const (
+	// ErrorCauseCanceled is used when the context of the operation was
+	// canceled by the client.
+	ErrorCauseCanceled = "canceled"
+	// ErrorCauseDeadlineExceeded is used when the deadline of the context of
+	// the operation passed.
+	ErrorCauseDeadlineExceeded = "deadline_exceeded"
+	// ErrorCauseStatementTimeout is used when the server canceled the
+	// statement because it ran longer than statement_timeout.
+	ErrorCauseStatementTimeout = "statement_timeout"
+	// ErrorCauseLockTimeout is used when the server canceled the statement
+	// because it waited longer than lock_timeout for a lock.
+	ErrorCauseLockTimeout = "lock_timeout"
+	// ErrorCauseQueryCanceled is used when the statement was canceled on the
+	// server for another reason, such as pg_cancel_backend.
+	ErrorCauseQueryCanceled = "query_canceled"
+)
+
+// errorCause returns why the operation run with ctx was interrupted with
+// err, or "" if it was not. ctx is only consulted when err is a cancellation,
+// because pgx cancels the statement on the server when the context is done,
+// which surfaces as a query_canceled (57014) error.
+func errorCause(ctx context.Context, err error) string {
+	if err == nil {
+		return ""
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorCauseDeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		return ErrorCauseCanceled
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return ""
+	}
+
+	switch {
+	case pgErr.Code == "57014" && errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return ErrorCauseDeadlineExceeded
+	case pgErr.Code == "57014" && errors.Is(ctx.Err(), context.Canceled):
+		return ErrorCauseCanceled
+	case pgErr.Code == "57014" && strings.Contains(pgErr.Message, "statement timeout"):
+		return ErrorCauseStatementTimeout
+	case pgErr.Code == "57014":
+		return ErrorCauseQueryCanceled
+	case pgErr.Code == "55P03" && strings.Contains(pgErr.Message, "lock timeout"):
+		return ErrorCauseLockTimeout
+	}
+
+	return ""
+}
+
+// errorCauseAttributes returns the pgx.error.cause attribute of an operation
+// interrupted with err, if any.
+func errorCauseAttributes(ctx context.Context, err error) []attribute.KeyValue {
+	if cause := errorCause(ctx, err); cause != "" {
+		return []attribute.KeyValue{ErrorCauseKey.String(cause)}
+	}
+	return nil
+}
+
+// timeoutAttributes returns the attributes describing the time an operation
+// started with ctx on conn is allowed to take.
+func timeoutAttributes(ctx context.Context, conn *pgx.Conn) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+
+	if deadline, ok := ctx.Deadline(); ok {
+		attrs = append(attrs, DeadlineRemainingKey.Float64(milliseconds(time.Until(deadline))))
+	}
+
+	if conn != nil {
+		timeouts := connSessionTimeouts(conn).timeouts
+		if ms, ok := timeouts[statementTimeoutParam]; ok {
+			attrs = append(attrs, StatementTimeoutKey.Float64(ms))
+		}
+		if ms, ok := timeouts[lockTimeoutParam]; ok {
+			attrs = append(attrs, LockTimeoutKey.Float64(ms))
+		}
+	}
+
+	return attrs
+}
+
+const (
+	statementTimeoutParam = "statement_timeout"
+	lockTimeoutParam      = "lock_timeout"
+)
+
+// connSessionTimeoutsKey is the pgconn.PgConn custom data key under which
+// the session timeouts of the connection are tracked.
+const connSessionTimeoutsKey = "github.com/piusalfred/otelpgx.sessionTimeouts"
+
+// sessionTimeouts are the statement_timeout and lock_timeout of a session in
+// milliseconds, by parameter name. Parameters left to the server default
+// are absent.
+//
+// They are best-effort: they are the runtime parameters of the connection,
+// as changed by the SET, RESET and DISCARD statements traced on it since.
+// Timeouts set otherwise, such as with set_config() or as role or database
+// defaults, are not seen.
+type sessionTimeouts map[string]float64
+
+// sessionTimeoutState tracks the session timeouts of a connection.
+type sessionTimeoutState struct {
+	timeouts sessionTimeouts
+
+	// saved are the timeouts from before the first change made in the
+	// current transaction, restored if the transaction rolls back.
+	saved sessionTimeouts
+}
+
+// connSessionTimeouts returns the session timeouts of conn, initialized
+// from the runtime parameters of its config.
+func connSessionTimeouts(conn *pgx.Conn) *sessionTimeoutState {
+	data := conn.PgConn().CustomData()
+	if state, ok := data[connSessionTimeoutsKey].(*sessionTimeoutState); ok {
+		return state
+	}
+
+	state := &sessionTimeoutState{timeouts: make(sessionTimeouts)}
+	state.timeouts.reset(conn.Config().RuntimeParams, "")
+
+	if data != nil {
+		data[connSessionTimeoutsKey] = state
+	}
+
+	return state
+}
+
+type sessionStatementKey struct{}
+
+// withSessionStatement returns ctx carrying sql if it is a SET, RESET or
+// DISCARD statement, for trackSessionTimeouts to apply it once it succeeded.
+func withSessionStatement(ctx context.Context, sql string) context.Context {
+	if !isSessionStatement(sql) {
+		return ctx
+	}
+	return context.WithValue(ctx, sessionStatementKey{}, sql)
+}
+
+// trackSessionTimeouts updates the session timeouts of conn after the
+// statement carried by ctx, if any, succeeded, and once the transaction
+// changing them ended.
+func trackSessionTimeouts(ctx context.Context, conn *pgx.Conn, tag pgconn.CommandTag, err error) {
+	if conn == nil {
+		return
+	}
+
+	if sql, ok := ctx.Value(sessionStatementKey{}).(string); ok && err == nil {
+		applySessionStatement(conn, sql)
+	}
+
+	endSessionTransaction(conn, tag, err)
+}
+
+// applySessionStatement updates the session timeouts of conn after sql
+// succeeded on it.
+func applySessionStatement(conn *pgx.Conn, sql string) {
+	if conn == nil || !isSessionStatement(sql) {
+		return
+	}
+
+	state := connSessionTimeouts(conn)
+	if state.saved == nil && conn.PgConn().TxStatus() != 'I' {
+		state.saved = maps.Clone(state.timeouts)
+	}
+
+	state.timeouts.apply(sql, conn.Config().RuntimeParams)
+}
+
+// endSessionTransaction restores the session timeouts changed in the
+// transaction of conn if it ended with a rollback, given the command tag and
+// error of the last statement run on conn. A failed COMMIT rolls back too.
+func endSessionTransaction(conn *pgx.Conn, tag pgconn.CommandTag, err error) {
+	state, ok := conn.PgConn().CustomData()[connSessionTimeoutsKey].(*sessionTimeoutState)
+	if !ok || state.saved == nil || conn.PgConn().TxStatus() != 'I' {
+		return
+	}
+
+	if err != nil || tag.String() == "ROLLBACK" {
+		state.timeouts = state.saved
+	}
+	state.saved = nil
+}
+
+// isSessionStatement reports whether sql is a SET, RESET or DISCARD
+// statement. Most statements are told apart without being tokenized.
+func isSessionStatement(sql string) bool {
+	return mayBeSessionStatement(sql) && isStatementOf(OperationQuery, sql, "SET", "RESET", "DISCARD")
+}
+
+// mayBeSessionStatement reports whether sql may be a SET, RESET or DISCARD
+// statement, without tokenizing it.
+func mayBeSessionStatement(sql string) bool {
+	sql = strings.TrimLeft(sql, " \t\n\r\f\v(")
+	if strings.HasPrefix(sql, "--") || strings.HasPrefix(sql, "/*") {
+		return true
+	}
+
+	for _, kw := range []string{"SET", "RESET", "DISCARD"} {
+		if len(sql) >= len(kw) && strings.EqualFold(sql[:len(kw)], kw) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// apply updates the timeouts changed by the SET, RESET or DISCARD statement
+// sql. runtimeParams are the parameters the session was started with, which
+// RESET restores. SET LOCAL is ignored, as it only lasts until the end of
+// the transaction.
+func (s sessionTimeouts) apply(sql string, runtimeParams map[string]string) {
+	tokens := statementTokens(sql)
+	if len(tokens) < 2 {
+		return
+	}
+
+	switch {
+	case tokens[0].IsKeyword("DISCARD") && tokens[1].IsKeyword("ALL"):
+		s.reset(runtimeParams, "")
+	case tokens[0].IsKeyword("RESET") && tokens[1].IsKeyword("ALL"):
+		s.reset(runtimeParams, "")
+	case tokens[0].IsKeyword("RESET"):
+		s.reset(runtimeParams, strings.ToLower(tokens[1].Text))
+	case tokens[0].IsKeyword("SET"):
+		tokens = tokens[1:]
+		if tokens[0].IsKeyword("LOCAL") {
+			return
+		}
+		if tokens[0].IsKeyword("SESSION") {
+			tokens = tokens[1:]
+		}
+		if len(tokens) < 3 || (tokens[1].Text != "=" && !tokens[1].IsKeyword("TO")) {
+			return
+		}
+
+		param := strings.ToLower(tokens[0].Text)
+		if param != statementTimeoutParam && param != lockTimeoutParam {
+			return
+		}
+
+		if tokens[2].IsKeyword("DEFAULT") {
+			s.reset(runtimeParams, param)
+			return
+		}
+
+		value := tokens[2].Text
+		switch {
+		case tokens[2].Kind == sqltoken.String:
+			value, _ = stringConstant(value)
+		case tokens[2].Kind == sqltoken.Number && len(tokens) > 3 && tokens[3].Kind == sqltoken.Ident:
+			// An unquoted value with a unit, such as 5s.
+			value += tokens[3].Text
+		}
+		if ms, ok := parseTimeout(value); ok {
+			s[param] = ms
+		} else {
+			delete(s, param)
+		}
+	}
+}
+
+// stringConstant returns the value of the string constant text, such as
+// '5s', E'5s' or U&'5s', or false if text is unterminated or is a bit string
+// constant. Escapes other than doubled quotes are not processed.
+func stringConstant(text string) (string, bool) {
+	switch {
+	case strings.HasPrefix(text, "E"), strings.HasPrefix(text, "e"):
+		text = text[1:]
+	case strings.HasPrefix(text, "U&"), strings.HasPrefix(text, "u&"):
+		text = text[2:]
+	}
+
+	if len(text) < 2 || text[0] != '\'' || text[len(text)-1] != '\'' {
+		return "", false
+	}
+
+	return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), true
+}
+
+// reset restores param, or all the timeouts if param is empty, to their
+// value in runtimeParams.
+func (s sessionTimeouts) reset(runtimeParams map[string]string, param string) {
+	for _, p := range []string{statementTimeoutParam, lockTimeoutParam} {
+		if param != "" && param != p {
+			continue
+		}
+
+		if ms, ok := parseTimeout(runtimeParams[p]); ok {
+			s[p] = ms
+		} else {
+			delete(s, p)
+		}
+	}
+}
+
+// timeoutUnits are the units of time accepted by PostgreSQL, in
+// milliseconds.
+var timeoutUnits = map[string]float64{
+	"us":  0.001,
+	"ms":  1,
+	"s":   1000,
+	"min": 60 * 1000,
+	"h":   60 * 60 * 1000,
+	"d":   24 * 60 * 60 * 1000,
+}
+
+// parseTimeout parses a PostgreSQL timeout setting such as 5000, 5s,
+// '1 min' or '1h30min' and returns it in milliseconds, the default unit.
+func parseTimeout(value string) (float64, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	var total float64
+	for value != "" {
+		i := 0
+		for i < len(value) && (value[i] >= '0' && value[i] <= '9' || value[i] == '.') {
+			i++
+		}
+
+		n, err := strconv.ParseFloat(value[:i], 64)
+		if err != nil {
+			return 0, false
+		}
+		value = strings.TrimLeft(value[i:], " ")
+
+		j := 0
+		for j < len(value) && (value[j] >= 'a' && value[j] <= 'z' || value[j] >= 'A' && value[j] <= 'Z') {
+			j++
+		}
+
+		unit := value[:j]
+		value = strings.TrimLeft(value[j:], " ")
+
+		factor := 1.0
+		if unit != "" {
+			var ok bool
+			if factor, ok = timeoutUnits[unit]; !ok {
+				return 0, false
+			}
+		}
+
+		total += n * factor
+	}
+
+	return total, true
+}
@@ -0,0 +1,188 @@
+package otelpgx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestErrorCause(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	queryCanceled := &pgconn.PgError{Code: "57014", Message: "canceling statement due to user request"}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want string
+	}{
+		{name: "No error", ctx: context.Background(), err: nil, want: ""},
+		{name: "Other error", ctx: context.Background(), err: errors.New("boom"), want: ""},
+		{name: "Canceled", ctx: context.Background(), err: fmt.Errorf("query: %w", context.Canceled), want: ErrorCauseCanceled},
+		{name: "Deadline exceeded", ctx: context.Background(), err: context.DeadlineExceeded, want: ErrorCauseDeadlineExceeded},
+		{name: "Canceled context", ctx: canceled, err: queryCanceled, want: ErrorCauseCanceled},
+		{name: "Expired context", ctx: expired, err: queryCanceled, want: ErrorCauseDeadlineExceeded},
+		{
+			name: "Statement timeout",
+			ctx:  context.Background(),
+			err:  &pgconn.PgError{Code: "57014", Message: "canceling statement due to statement timeout"},
+			want: ErrorCauseStatementTimeout,
+		},
+		{
+			name: "Lock timeout",
+			ctx:  context.Background(),
+			err:  &pgconn.PgError{Code: "55P03", Message: "canceling statement due to lock timeout"},
+			want: ErrorCauseLockTimeout,
+		},
+		{
+			name: "Lock not available",
+			ctx:  context.Background(),
+			err:  &pgconn.PgError{Code: "55P03", Message: `could not obtain lock on row in relation "users"`},
+			want: "",
+		},
+		{name: "Query canceled", ctx: context.Background(), err: queryCanceled, want: ErrorCauseQueryCanceled},
+		{name: "Other error with expired context", ctx: expired, err: &pgconn.PgError{Code: "23505"}, want: ""},
+		{name: "Statement timeout with canceled context", ctx: canceled, err: errors.New("boom"), want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorCause(tt.ctx, tt.err); got != tt.want {
+				t.Errorf("errorCause() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTimeout(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   float64
+		wantOK bool
+	}{
+		{value: "0", want: 0, wantOK: true},
+		{value: "5000", want: 5000, wantOK: true},
+		{value: "500ms", want: 500, wantOK: true},
+		{value: "1.5s", want: 1500, wantOK: true},
+		{value: "2 min", want: 120000, wantOK: true},
+		{value: "1h", want: 3600000, wantOK: true},
+		{value: "1h30min", want: 5400000, wantOK: true},
+		{value: "1 h 30 min", want: 5400000, wantOK: true},
+		{value: "", wantOK: false},
+		{value: "5 parsecs", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := parseTimeout(tt.value)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("parseTimeout() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestSessionTimeouts_apply(t *testing.T) {
+	runtimeParams := map[string]string{statementTimeoutParam: "30s"}
+
+	tests := []struct {
+		name string
+		sql  string
+		want sessionTimeouts
+	}{
+		{name: "SET", sql: "SET statement_timeout = 5000", want: sessionTimeouts{statementTimeoutParam: 5000, lockTimeoutParam: 100}},
+		{name: "SET TO", sql: "set lock_timeout to '1s';", want: sessionTimeouts{statementTimeoutParam: 10000, lockTimeoutParam: 1000}},
+		{name: "SET escape string", sql: "SET statement_timeout = E'5s'", want: sessionTimeouts{statementTimeoutParam: 5000, lockTimeoutParam: 100}},
+		{name: "SET Unicode string", sql: "SET lock_timeout = U&'1min'", want: sessionTimeouts{statementTimeoutParam: 10000, lockTimeoutParam: 60000}},
+		{name: "SET unterminated string", sql: "SET statement_timeout = '", want: sessionTimeouts{lockTimeoutParam: 100}},
+		{name: "SET with unit", sql: "SET statement_timeout = 5s", want: sessionTimeouts{statementTimeoutParam: 5000, lockTimeoutParam: 100}},
+		{name: "SET SESSION", sql: "SET SESSION statement_timeout = 0", want: sessionTimeouts{statementTimeoutParam: 0, lockTimeoutParam: 100}},
+		{name: "SET LOCAL", sql: "SET LOCAL statement_timeout = 0", want: sessionTimeouts{statementTimeoutParam: 10000, lockTimeoutParam: 100}},
+		{name: "SET DEFAULT", sql: "SET statement_timeout TO DEFAULT", want: sessionTimeouts{statementTimeoutParam: 30000, lockTimeoutParam: 100}},
+		{name: "SET other", sql: "SET search_path = app", want: sessionTimeouts{statementTimeoutParam: 10000, lockTimeoutParam: 100}},
+		{name: "RESET", sql: "RESET lock_timeout", want: sessionTimeouts{statementTimeoutParam: 10000}},
+		{name: "RESET ALL", sql: "RESET ALL", want: sessionTimeouts{statementTimeoutParam: 30000}},
+		{name: "DISCARD ALL", sql: "DISCARD ALL", want: sessionTimeouts{statementTimeoutParam: 30000}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sessionTimeouts{statementTimeoutParam: 10000, lockTimeoutParam: 100}
+			got.apply(tt.sql, runtimeParams)
+
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("apply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTracer_sessionTimeouts(t *testing.T) {
+	server := newTestServer(t)
+
+	config := server.connConfig(t)
+	config.Tracer = NewTracer()
+	config.RuntimeParams[statementTimeoutParam] = "30s"
+	conn := server.connect(t, config)
+
+	steps := []struct {
+		sql  string
+		want sessionTimeouts
+	}{
+		{sql: "SET statement_timeout = '1h30min'", want: sessionTimeouts{statementTimeoutParam: 5400000}},
+		{sql: "BEGIN", want: sessionTimeouts{statementTimeoutParam: 5400000}},
+		{sql: "SET statement_timeout = 5000", want: sessionTimeouts{statementTimeoutParam: 5000}},
+		{sql: "SET lock_timeout = '1s'", want: sessionTimeouts{statementTimeoutParam: 5000, lockTimeoutParam: 1000}},
+		{sql: "ROLLBACK", want: sessionTimeouts{statementTimeoutParam: 5400000}},
+		{sql: "BEGIN", want: sessionTimeouts{statementTimeoutParam: 5400000}},
+		{sql: "SET lock_timeout = 100", want: sessionTimeouts{statementTimeoutParam: 5400000, lockTimeoutParam: 100}},
+		{sql: "COMMIT", want: sessionTimeouts{statementTimeoutParam: 5400000, lockTimeoutParam: 100}},
+		{sql: "RESET ALL", want: sessionTimeouts{statementTimeoutParam: 30000}},
+	}
+
+	for _, step := range steps {
+		if _, err := conn.Exec(context.Background(), step.sql); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := connSessionTimeouts(conn).timeouts; fmt.Sprint(got) != fmt.Sprint(step.want) {
+			t.Errorf("after %q: timeouts = %v, want %v", step.sql, got, step.want)
+		}
+	}
+}
+
+func TestTracer_timeouts(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tr := NewTracer(WithTracerProvider(tp))
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+
+	ctx = tr.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "SELECT pg_sleep(10)"})
+	cancel()
+	tr.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: &pgconn.PgError{Code: "57014"}})
+	parent.End()
+
+	attrs := attribute.NewSet(recorder.Ended()[0].Attributes()...)
+
+	if remaining, ok := attrs.Value(DeadlineRemainingKey); !ok || remaining.AsFloat64() <= 0 || remaining.AsFloat64() > 60000 {
+		t.Errorf("%v = %v, want between 0 and 60000", DeadlineRemainingKey, remaining.Emit())
+	}
+	if cause, _ := attrs.Value(ErrorCauseKey); cause.AsString() != ErrorCauseCanceled {
+		t.Errorf("%v = %q, want %q", ErrorCauseKey, cause.AsString(), ErrorCauseCanceled)
+	}
+}
@@ -2,17 +2,20 @@ package otelpgx
 
 import (
 	"context"
-	"database/sql"
 	"errors"
-	"fmt"
 	"runtime/debug"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/piusalfred/otelpgx/internal"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
 	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -21,6 +24,13 @@ const (
 	tracerName = "github.com/piusalfred/otelpgx"
 
 	sqlOperationUnknown = "UNKNOWN"
+
+	// operationDurationName is the name of the per-operation duration
+	// histogram, see https://opentelemetry.io/docs/specs/semconv/database/database-metrics/.
+	operationDurationName = "db.client.operation.duration"
+
+	// slowQueriesName is the name of the slow query counter.
+	slowQueriesName = "db.client.slow_queries"
 )
 
 const (
@@ -35,6 +45,8 @@ const (
 	// SQLStateKey represents PostgreSQL error code,
 	// see https://www.postgresql.org/docs/current/errcodes-appendix.html.
 	SQLStateKey = attribute.Key("pgx.sql_state")
+	// ErrorKey represents whether the operation failed.
+	ErrorKey = attribute.Key("pgx.error")
 )
 
 // Tracer is a wrapper around the pgx tracer interfaces which instrument
@@ -46,56 +58,233 @@ type Tracer struct {
 	spanNameFunc      SpanNameFunc
 	logSQLStatement   bool
 	includeParams     bool
+	obfuscateSQL      bool
+	operationSpanName bool
+	semConvStability  SemConvStability
+	paramFormatter    ParamFormatter
+	paramRedactor     ParamRedactor
+	connAttrFields    []ConnAttribute
+	sqlCommenter      bool
+	spanFilters       []SpanFilter
+	batchQueryEvents  bool
+	errorClassifiers  []ErrorClassifier
+	errorType         ErrorTypeFunc
+	pgErrorDetails    bool
+
+	slowQueryThreshold time.Duration
+	slowQueryLogger    *Logger
+
+	// operationDuration and slowQueries are nil unless a meter provider
+	// was configured.
+	operationDuration metric.Float64Histogram
+	slowQueries       metric.Int64Counter
+
+	// poolAttrs caches the attributes of the pools traced, see
+	// poolAttributes.
+	poolAttrs sync.Map
+
+	// trackNotices is set once OnNotice is called, see pushNoticeSpan.
+	trackNotices atomic.Bool
 }
 
 type tracerConfig struct {
 	tp                trace.TracerProvider
+	mp                metric.MeterProvider
 	attrs             []attribute.KeyValue
 	trimQuerySpanName bool
 	spanNameFunc      SpanNameFunc
 	logSQLStatement   bool
 	includeParams     bool
+	obfuscateSQL      bool
+	operationSpanName bool
+	semConvStability  SemConvStability
+	paramFormatter    ParamFormatter
+	paramRedactor     ParamRedactor
+	connAttrFields    []ConnAttribute
+	sqlCommenter      bool
+	spanFilters       []SpanFilter
+	batchQueryEvents  bool
+	errorClassifiers  []ErrorClassifier
+	errorType         ErrorTypeFunc
+	pgErrorDetails    bool
+
+	slowQueryThreshold time.Duration
+	slowQueryLogger    *Logger
 }
 
 // NewTracer returns a new Tracer.
 func NewTracer(opts ...Option) *Tracer {
 	cfg := &tracerConfig{
-		tp: otel.GetTracerProvider(),
-		attrs: []attribute.KeyValue{
-			semconv.DBSystemPostgreSQL,
-		},
+		tp:                otel.GetTracerProvider(),
 		trimQuerySpanName: false,
 		spanNameFunc:      nil,
 		logSQLStatement:   true,
 		includeParams:     false,
+		semConvStability:  semConvStabilityFromEnv(),
+		paramFormatter:    NewParamFormatter(defaultParamMaxLength),
+		connAttrFields:    defaultConnAttributes,
+		errorClassifiers:  defaultErrorClassifiers,
+		errorType:         ErrorTypeSQLState,
 	}
 
 	for _, opt := range opts {
 		opt.apply(cfg)
 	}
 
-	return &Tracer{
+	t := &Tracer{
 		tracer:            cfg.tp.Tracer(tracerName, trace.WithInstrumentationVersion(findOwnImportedVersion())),
-		attrs:             cfg.attrs,
+		attrs:             append(cfg.semConvStability.systemAttributes(), cfg.attrs...),
 		trimQuerySpanName: cfg.trimQuerySpanName,
 		spanNameFunc:      cfg.spanNameFunc,
 		logSQLStatement:   cfg.logSQLStatement,
 		includeParams:     cfg.includeParams,
+		obfuscateSQL:      cfg.obfuscateSQL,
+		operationSpanName: cfg.operationSpanName,
+		semConvStability:  cfg.semConvStability,
+		paramFormatter:    cfg.paramFormatter,
+		paramRedactor:     cfg.paramRedactor,
+		connAttrFields:    cfg.connAttrFields,
+		sqlCommenter:      cfg.sqlCommenter,
+		spanFilters:       cfg.spanFilters,
+		batchQueryEvents:  cfg.batchQueryEvents,
+		errorClassifiers:  cfg.errorClassifiers,
+		errorType:         cfg.errorType,
+		pgErrorDetails:    cfg.pgErrorDetails,
+
+		slowQueryThreshold: cfg.slowQueryThreshold,
+		slowQueryLogger:    cfg.slowQueryLogger,
+	}
+
+	if cfg.mp != nil {
+		t.createInstruments(cfg.mp.Meter(internal.MeterName, metric.WithInstrumentationVersion(findOwnImportedVersion())))
+	}
+
+	return t
+}
+
+// createInstruments creates the instruments used by the Tracer. Errors are
+// reported to the global error handler, and the affected instrument is left
+// nil so that recording it is skipped.
+func (t *Tracer) createInstruments(meter metric.Meter) {
+	var err error
+
+	t.operationDuration, err = meter.Float64Histogram(
+		operationDurationName,
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of database client operations."),
+		metric.WithExplicitBucketBoundaries(0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10),
+	)
+	if err != nil {
+		otel.Handle(err)
+		t.operationDuration = nil
+	}
+
+	t.slowQueries, err = meter.Int64Counter(
+		slowQueriesName,
+		metric.WithUnit("{query}"),
+		metric.WithDescription("Number of queries, batches and copies slower than the slow query threshold."),
+	)
+	if err != nil {
+		otel.Handle(err)
+		t.slowQueries = nil
 	}
 }
 
-func recordError(span trace.Span, err error) {
-	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+type operationStartKey struct{}
+
+// operationStart is stored in the context by the Trace*Start methods so the
+// matching Trace*End method can record the operation's duration, whether or
+// not a span was started.
+type operationStart struct {
+	name      string
+	statement string
+	start     time.Time
+}
+
+// startOperation records the start of an operation in ctx when duration
+// metrics or slow query detection are enabled. statement is the SQL
+// statement or the table name of the operation, if any.
+func (t *Tracer) startOperation(ctx context.Context, name, statement string) context.Context {
+	if t.operationDuration == nil && t.slowQueryThreshold <= 0 {
+		return ctx
+	}
+
+	return context.WithValue(ctx, operationStartKey{}, operationStart{
+		name:      name,
+		statement: statement,
+		start:     time.Now(),
+	})
+}
+
+// endOperation records the duration of the operation started in ctx. It
+// returns the operation and its duration, or false if no operation was
+// started in ctx.
+func (t *Tracer) endOperation(ctx context.Context, err error) (operationStart, time.Duration, bool) {
+	op, ok := ctx.Value(operationStartKey{}).(operationStart)
+	if !ok {
+		return operationStart{}, 0, false
+	}
+
+	elapsed := time.Since(op.start)
+
+	if t.operationDuration != nil {
+		t.operationDuration.Record(ctx, elapsed.Seconds(), metric.WithAttributes(t.operationMetricAttributes(ctx, op, err)...))
+	}
+
+	return op, elapsed, true
+}
+
+// operationMetricAttributes returns the metric attributes of an operation.
+func (t *Tracer) operationMetricAttributes(ctx context.Context, op operationStart, err error) []attribute.KeyValue {
+	failed := t.classifyError(err) == codes.Error
+
+	attrs := make([]attribute.KeyValue, 0, len(t.attrs)+6)
+	attrs = append(attrs, t.attrs...)
+	attrs = append(attrs, t.semConvStability.operationAttributes(op.name)...)
+	attrs = append(attrs, ErrorKey.Bool(failed))
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		attrs = append(attrs, SQLStateKey.String(pgErr.Code))
+	}
+
+	attrs = append(attrs, errorCauseAttributes(ctx, err)...)
+
+	if failed {
+		attrs = append(attrs, t.errorAttributes(err)...)
+	}
+
+	return attrs
+}
+
+// recordError sets the span status according to the error classifiers and,
+// for failed operations, records err on span. ctx is the context the
+// operation was run with.
+func (t *Tracer) recordError(ctx context.Context, span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+
+	span.SetAttributes(t.pgErrorAttributes(err)...)
+	span.SetAttributes(errorCauseAttributes(ctx, err)...)
+
+	switch t.classifyError(err) {
+	case codes.Error:
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) {
-			span.SetAttributes(SQLStateKey.String(pgErr.Code))
-		}
+		span.SetAttributes(t.errorAttributes(err)...)
+	case codes.Ok:
+		span.SetStatus(codes.Ok, "")
 	}
 }
 
+// errorAttributes returns the attributes describing the error of a failed
+// operation.
+func (t *Tracer) errorAttributes(err error) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{semconv.ErrorTypeKey.String(t.errorType(err))}
+	return append(attrs, t.semConvStability.responseStatusAttributes(err)...)
+}
+
 // sqlOperationName attempts to get the first 'word' from a given SQL query, which usually
 // is the operation name (e.g. 'SELECT').
 func (t *Tracer) sqlOperationName(stmt string) string {
@@ -118,14 +307,10 @@ func (t *Tracer) sqlOperationName(stmt string) string {
 
 // connectionAttributesFromConfig returns a slice of SpanStartOptions that contain
 // attributes from the given connection config.
-func connectionAttributesFromConfig(config *pgx.ConnConfig) []trace.SpanStartOption {
+func (t *Tracer) connectionAttributesFromConfig(config *pgx.ConnConfig) []trace.SpanStartOption {
 	if config != nil {
 		return []trace.SpanStartOption{
-			trace.WithAttributes(
-				semconv.NetPeerName(config.Host),
-				semconv.NetPeerPort(int(config.Port)),
-				semconv.DBUser(config.User),
-			),
+			trace.WithAttributes(t.connConfigAttributes(config)...),
 		}
 	}
 	return nil
@@ -134,45 +319,85 @@ func connectionAttributesFromConfig(config *pgx.ConnConfig) []trace.SpanStartOpt
 // TraceQueryStart is called at the beginning of Query, QueryRow, and Exec calls.
 // The returned context is used for the rest of the call and will be passed to TraceQueryEnd.
 func (t *Tracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	ctx = t.startOperation(ctx, t.sqlOperationName(data.SQL), data.SQL)
+	ctx = withSessionStatement(ctx, data.SQL)
+
 	if !trace.SpanFromContext(ctx).IsRecording() {
 		return ctx
 	}
 
+	if t.skipSpan(ctx, OperationQuery, data.SQL) {
+		return withoutSpan(ctx)
+	}
+
 	opts := []trace.SpanStartOption{
 		trace.WithSpanKind(trace.SpanKindClient),
 		trace.WithAttributes(t.attrs...),
 	}
 
 	if conn != nil {
-		opts = append(opts, connectionAttributesFromConfig(conn.Config())...)
+		opts = append(opts, trace.WithAttributes(t.connAttributes(conn)...))
 	}
 
+	opts = append(opts, trace.WithAttributes(timeoutAttributes(ctx, conn)...))
+
+	stmt := t.statement(data.SQL)
+
 	if t.logSQLStatement {
-		opts = append(opts, trace.WithAttributes(semconv.DBStatement(data.SQL)))
+		opts = append(opts, trace.WithAttributes(t.semConvStability.statementAttributes(stmt)...))
 		if t.includeParams {
-			opts = append(opts, trace.WithAttributes(makeParamsAttribute(data.Args)))
+			opts = append(opts, trace.WithAttributes(t.paramsAttribute(data.Args)))
 		}
 	}
 
-	spanName := "query " + data.SQL
+	spanName := "query " + stmt
 	if t.trimQuerySpanName {
-		spanName = "query " + t.sqlOperationName(data.SQL)
+		spanName = "query " + t.sqlOperationName(stmt)
+	}
+
+	if t.operationSpanName {
+		if name, attrs := t.operationTableSpanName(conn, data.SQL); name != "" {
+			spanName = name
+			opts = append(opts, trace.WithAttributes(attrs...))
+		}
+	}
+
+	state := &queryState{}
+
+	if mode, ok, hasArgs := queryExecMode(conn, data.Args); ok {
+		opts = append(opts, trace.WithAttributes(QueryExecModeKey.String(execModeName(mode))))
+		state.cachesStatement = cachesStatements(mode) && hasArgs
 	}
 
 	ctx, _ = t.tracer.Start(ctx, spanName, opts...)
+	t.pushNoticeSpan(ctx, conn)
 
-	return ctx
+	return context.WithValue(ctx, queryStateKey{}, state)
 }
 
 // TraceQueryEnd is called at the end of Query, QueryRow, and Exec calls.
-func (t *Tracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+func (t *Tracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	op, elapsed, ok := t.endOperation(ctx, data.Err)
+
 	span := trace.SpanFromContext(ctx)
-	recordError(span, data.Err)
+	t.recordError(ctx, span, data.Err)
+
+	if ok {
+		t.detectSlowQuery(ctx, span, op, elapsed, data.Err)
+	}
 
 	if data.Err == nil {
 		span.SetAttributes(RowsAffectedKey.Int64(data.CommandTag.RowsAffected()))
 	}
 
+	trackSessionTimeouts(ctx, conn, data.CommandTag, data.Err)
+
+	if state, ok := ctx.Value(queryStateKey{}).(*queryState); ok && state.cachesStatement {
+		span.SetAttributes(StatementCacheHitKey.Bool(!state.prepared))
+	}
+
+	t.popNoticeSpan(ctx, conn)
+
 	span.End()
 }
 
@@ -180,34 +405,57 @@ func (t *Tracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQ
 // returned context is used for the rest of the call and will be passed to
 // TraceCopyFromEnd.
 func (t *Tracer) TraceCopyFromStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	ctx = t.startOperation(ctx, "COPY", data.TableName.Sanitize())
+
 	if !trace.SpanFromContext(ctx).IsRecording() {
 		return ctx
 	}
 
+	if t.skipSpan(ctx, OperationCopyFrom, data.TableName.Sanitize()) {
+		return withoutSpan(ctx)
+	}
+
 	opts := []trace.SpanStartOption{
 		trace.WithSpanKind(trace.SpanKindClient),
 		trace.WithAttributes(t.attrs...),
-		trace.WithAttributes(semconv.DBSQLTable(data.TableName.Sanitize())),
+		trace.WithAttributes(t.semConvStability.tableAttributes(data.TableName.Sanitize())...),
+	}
+
+	if len(data.ColumnNames) > 0 {
+		opts = append(opts, trace.WithAttributes(CopyColumnsKey.StringSlice(data.ColumnNames)))
 	}
 
 	if conn != nil {
-		opts = append(opts, connectionAttributesFromConfig(conn.Config())...)
+		opts = append(opts, trace.WithAttributes(t.connAttributes(conn)...))
 	}
 
+	opts = append(opts, trace.WithAttributes(timeoutAttributes(ctx, conn)...))
+
 	ctx, _ = t.tracer.Start(ctx, "copy_from "+data.TableName.Sanitize(), opts...)
+	t.pushNoticeSpan(ctx, conn)
 
-	return ctx
+	return context.WithValue(ctx, copyStartKey{}, time.Now())
 }
 
 // TraceCopyFromEnd is called at the end of CopyFrom calls.
-func (t *Tracer) TraceCopyFromEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromEndData) {
+func (t *Tracer) TraceCopyFromEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	op, elapsed, ok := t.endOperation(ctx, data.Err)
+
 	span := trace.SpanFromContext(ctx)
-	recordError(span, data.Err)
+	t.recordError(ctx, span, data.Err)
+
+	if ok {
+		t.detectSlowQuery(ctx, span, op, elapsed, data.Err)
+	}
 
 	if data.Err == nil {
-		span.SetAttributes(RowsAffectedKey.Int64(data.CommandTag.RowsAffected()))
+		// The rows are encoded by pgx, so the bytes streamed are unknown.
+		start, _ := ctx.Value(copyStartKey{}).(time.Time)
+		span.SetAttributes(copyAttributes(data.CommandTag, -1, start)...)
 	}
 
+	t.popNoticeSpan(ctx, conn)
+
 	span.End()
 }
 
@@ -215,6 +463,8 @@ func (t *Tracer) TraceCopyFromEnd(ctx context.Context, _ *pgx.Conn, data pgx.Tra
 // context is used for the rest of the call and will be passed to
 // TraceBatchQuery and TraceBatchEnd.
 func (t *Tracer) TraceBatchStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	ctx = t.startOperation(ctx, "BATCH", "")
+
 	if !trace.SpanFromContext(ctx).IsRecording() {
 		return ctx
 	}
@@ -231,48 +481,100 @@ func (t *Tracer) TraceBatchStart(ctx context.Context, conn *pgx.Conn, data pgx.T
 	}
 
 	if conn != nil {
-		opts = append(opts, connectionAttributesFromConfig(conn.Config())...)
+		opts = append(opts, trace.WithAttributes(t.connAttributes(conn)...))
 	}
 
+	opts = append(opts, trace.WithAttributes(timeoutAttributes(ctx, conn)...))
+
 	ctx, _ = t.tracer.Start(ctx, "batch start", opts...)
+	t.pushNoticeSpan(ctx, conn)
 
-	return ctx
+	return context.WithValue(ctx, batchStateKey{}, &batchState{last: time.Now()})
 }
 
 // TraceBatchQuery is called at the after each query in a batch.
 func (t *Tracer) TraceBatchQuery(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchQueryData) {
+	index, start, end, timed := nextBatchQuery(ctx)
+
+	if t.sqlCommenter {
+		// pgx runs the query rewriters of batch queries, including the one
+		// added by Querier, before tracing them.
+		data.SQL = stripSQLComment(data.SQL)
+	}
+
+	if data.Err == nil {
+		applySessionStatement(conn, data.SQL)
+	}
+
+	if t.skipSpan(ctx, OperationBatchQuery, data.SQL) {
+		return
+	}
+
+	if t.batchQueryEvents && timed {
+		t.addBatchQueryEvent(ctx, index, start, end, data)
+		return
+	}
+
 	opts := []trace.SpanStartOption{
 		trace.WithSpanKind(trace.SpanKindClient),
 		trace.WithAttributes(t.attrs...),
 	}
 
+	if timed {
+		opts = append(opts,
+			trace.WithTimestamp(start),
+			trace.WithAttributes(BatchIndexKey.Int(index)),
+		)
+	}
+
 	if conn != nil {
-		opts = append(opts, connectionAttributesFromConfig(conn.Config())...)
+		opts = append(opts, trace.WithAttributes(t.connAttributes(conn)...))
 	}
 
+	stmt := t.statement(data.SQL)
+
 	if t.logSQLStatement {
-		opts = append(opts, trace.WithAttributes(semconv.DBStatement(data.SQL)))
+		opts = append(opts, trace.WithAttributes(t.semConvStability.statementAttributes(stmt)...))
 		if t.includeParams {
-			opts = append(opts, trace.WithAttributes(makeParamsAttribute(data.Args)))
+			opts = append(opts, trace.WithAttributes(t.paramsAttribute(data.Args)))
 		}
 
 	}
 
-	spanName := "batch query " + data.SQL
+	spanName := "batch query " + stmt
 	if t.trimQuerySpanName {
-		spanName = "query " + t.sqlOperationName(data.SQL)
+		spanName = "batch query " + t.sqlOperationName(stmt)
+	}
+
+	if t.operationSpanName {
+		if name, attrs := t.operationTableSpanName(conn, data.SQL); name != "" {
+			spanName = name
+			opts = append(opts, trace.WithAttributes(attrs...))
+		}
 	}
 
 	_, span := t.tracer.Start(ctx, spanName, opts...)
-	recordError(span, data.Err)
+	t.recordError(ctx, span, data.Err)
 
-	span.End()
+	if timed {
+		span.End(trace.WithTimestamp(end))
+	} else {
+		span.End()
+	}
 }
 
 // TraceBatchEnd is called at the end of SendBatch calls.
-func (t *Tracer) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+func (t *Tracer) TraceBatchEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchEndData) {
+	op, elapsed, ok := t.endOperation(ctx, data.Err)
+
 	span := trace.SpanFromContext(ctx)
-	recordError(span, data.Err)
+	t.recordError(ctx, span, data.Err)
+
+	if ok {
+		t.detectSlowQuery(ctx, span, op, elapsed, data.Err)
+	}
+
+	t.popNoticeSpan(ctx, conn)
 
 	span.End()
 }
@@ -285,13 +587,17 @@ func (t *Tracer) TraceConnectStart(ctx context.Context, data pgx.TraceConnectSta
 		return ctx
 	}
 
+	if t.skipSpan(ctx, OperationConnect, "") {
+		return withoutSpan(ctx)
+	}
+
 	opts := []trace.SpanStartOption{
 		trace.WithSpanKind(trace.SpanKindClient),
 		trace.WithAttributes(t.attrs...),
 	}
 
 	if data.ConnConfig != nil {
-		opts = append(opts, connectionAttributesFromConfig(data.ConnConfig)...)
+		opts = append(opts, t.connectionAttributesFromConfig(data.ConnConfig)...)
 	}
 
 	ctx, _ = t.tracer.Start(ctx, "connect", opts...)
@@ -302,7 +608,13 @@ func (t *Tracer) TraceConnectStart(ctx context.Context, data pgx.TraceConnectSta
 // TraceConnectEnd is called at the end of Connect and ConnectConfig calls.
 func (t *Tracer) TraceConnectEnd(ctx context.Context, data pgx.TraceConnectEndData) {
 	span := trace.SpanFromContext(ctx)
-	recordError(span, data.Err)
+	t.recordError(ctx, span, data.Err)
+
+	if data.Conn != nil {
+		// Computes and caches the connection attributes before the
+		// connection is used.
+		span.SetAttributes(t.connAttributes(data.Conn)...)
+	}
 
 	span.End()
 }
@@ -311,51 +623,79 @@ func (t *Tracer) TraceConnectEnd(ctx context.Context, data pgx.TraceConnectEndDa
 // context is used for the rest of the call and will be passed to
 // TracePrepareEnd.
 func (t *Tracer) TracePrepareStart(ctx context.Context, conn *pgx.Conn, data pgx.TracePrepareStartData) context.Context {
+	if t.sqlCommenter {
+		// The statements prepared on behalf of queries were rewritten by
+		// Querier.
+		data.SQL = stripSQLComment(data.SQL)
+	}
+
+	// Prepares are recorded apart from the statements they prepare, whose
+	// durations already include the prepares run on their behalf.
+	ctx = t.startOperation(ctx, "PREPARE", data.SQL)
+
 	if !trace.SpanFromContext(ctx).IsRecording() {
 		return ctx
 	}
 
+	if t.skipSpan(ctx, OperationPrepare, data.SQL) {
+		return withoutSpan(ctx)
+	}
+
 	opts := []trace.SpanStartOption{
 		trace.WithSpanKind(trace.SpanKindClient),
 		trace.WithAttributes(t.attrs...),
 	}
 
 	if data.Name != "" {
-		trace.WithAttributes(PrepareStmtNameKey.String(data.Name))
+		opts = append(opts, trace.WithAttributes(PrepareStmtNameKey.String(data.Name)))
 	}
 
+	opts = append(opts, trace.WithAttributes(PrepareImplicitKey.Bool(markImplicitPrepare(ctx))))
+
 	if conn != nil {
-		opts = append(opts, connectionAttributesFromConfig(conn.Config())...)
+		opts = append(opts, trace.WithAttributes(t.connAttributes(conn)...))
 	}
 
+	opts = append(opts, trace.WithAttributes(timeoutAttributes(ctx, conn)...))
+
+	stmt := t.statement(data.SQL)
+
 	if t.logSQLStatement {
-		opts = append(opts, trace.WithAttributes(semconv.DBStatement(data.SQL)))
+		opts = append(opts, trace.WithAttributes(t.semConvStability.statementAttributes(stmt)...))
 	}
 
-	spanName := "prepare " + data.SQL
+	spanName := "prepare " + stmt
 	if t.trimQuerySpanName {
-		spanName = "prepare " + t.sqlOperationName(data.SQL)
+		spanName = "prepare " + t.sqlOperationName(stmt)
+	}
+
+	if t.operationSpanName {
+		if name, attrs := t.operationTableSpanName(conn, data.SQL); name != "" {
+			spanName = "prepare " + name
+			opts = append(opts, trace.WithAttributes(attrs...))
+		}
 	}
 
 	ctx, _ = t.tracer.Start(ctx, spanName, opts...)
+	t.pushNoticeSpan(ctx, conn)
 
 	return ctx
 }
 
 // TracePrepareEnd is called at the end of Prepare calls.
-func (t *Tracer) TracePrepareEnd(ctx context.Context, _ *pgx.Conn, data pgx.TracePrepareEndData) {
-	span := trace.SpanFromContext(ctx)
-	recordError(span, data.Err)
+func (t *Tracer) TracePrepareEnd(ctx context.Context, conn *pgx.Conn, data pgx.TracePrepareEndData) {
+	t.endOperation(ctx, data.Err)
 
-	span.End()
-}
+	span := trace.SpanFromContext(ctx)
+	t.recordError(ctx, span, data.Err)
 
-func makeParamsAttribute(args []any) attribute.KeyValue {
-	ss := make([]string, len(args))
-	for i := range args {
-		ss[i] = fmt.Sprintf("%+v", args[i])
+	if data.Err == nil {
+		span.SetAttributes(PrepareAlreadyPreparedKey.Bool(data.AlreadyPrepared))
 	}
-	return QueryParametersKey.StringSlice(ss)
+
+	t.popNoticeSpan(ctx, conn)
+
+	span.End()
 }
 
 func findOwnImportedVersion() string {
@@ -1,8 +1,16 @@
 package otelpgx
 
 import (
+	"context"
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	semconv "go.opentelemetry.io/otel/semconv/v1.25.0"
 )
 
 func TestTracer_sqlOperationName(t *testing.T) {
@@ -131,3 +139,89 @@ var defaultSpanNameFunc SpanNameFunc = func(query string) string {
 	}
 	return sqlOperationUnknown
 }
+
+func TestTracer_operationDurationPrepare(t *testing.T) {
+	server := newTestServer(t)
+
+	reader := sdkmetric.NewManualReader()
+	tr := NewTracer(
+		WithTracerMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+		WithSemConvStability(SemConvStabilityOld),
+	)
+
+	config := server.connConfig(t)
+	config.Tracer = tr
+	conn := server.connect(t, config)
+
+	// The statement cache misses, so the query is prepared first.
+	if _, err := conn.Exec(context.Background(), "SELECT * FROM users WHERE id = $1", "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+
+	hist, ok := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Histogram[float64])
+	if !ok {
+		t.Fatalf("unexpected metric data type %T", rm.ScopeMetrics[0].Metrics[0].Data)
+	}
+
+	got := make(map[string]uint64)
+	for _, dp := range hist.DataPoints {
+		op, _ := dp.Attributes.Value(semconv.DBOperationKey)
+		got[op.AsString()] += dp.Count
+	}
+
+	if want := map[string]uint64{"SELECT": 1, "PREPARE": 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("data points per operation = %v, want %v", got, want)
+	}
+}
+
+func TestTracer_operationDuration(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	tr := NewTracer(WithTracerMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))))
+
+	// No span is recording, the duration must still be recorded.
+	ctx := tr.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "SELECT 1"})
+	tr.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+
+	ctx = tr.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "INSERT INTO users VALUES ($1)"})
+	tr.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: &pgconn.PgError{Code: "23505"}})
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rm.ScopeMetrics) != 1 || len(rm.ScopeMetrics[0].Metrics) != 1 {
+		t.Fatalf("expected a single metric, got %+v", rm.ScopeMetrics)
+	}
+
+	m := rm.ScopeMetrics[0].Metrics[0]
+	if m.Name != operationDurationName {
+		t.Errorf("metric name = %v, want %v", m.Name, operationDurationName)
+	}
+
+	hist, ok := m.Data.(metricdata.Histogram[float64])
+	if !ok {
+		t.Fatalf("unexpected metric data type %T", m.Data)
+	}
+
+	want := map[string]bool{"SELECT": false, "INSERT": true}
+	if len(hist.DataPoints) != len(want) {
+		t.Fatalf("got %d data points, want %d", len(hist.DataPoints), len(want))
+	}
+
+	for _, dp := range hist.DataPoints {
+		op, _ := dp.Attributes.Value(semconv.DBOperationKey)
+		failed, _ := dp.Attributes.Value(ErrorKey)
+		if wantFailed, ok := want[op.AsString()]; !ok || failed.AsBool() != wantFailed {
+			t.Errorf("unexpected data point attributes %v", dp.Attributes.ToSlice())
+		}
+		if dp.Count != 1 {
+			t.Errorf("data point count = %d, want 1", dp.Count)
+		}
+	}
+}
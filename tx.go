@@ -0,0 +1,234 @@
+package otelpgx
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// TxIsoLevelKey represents the isolation level requested for a transaction.
+	TxIsoLevelKey = attribute.Key("pgx.tx.isolation_level")
+	// TxAccessModeKey represents the access mode requested for a transaction.
+	TxAccessModeKey = attribute.Key("pgx.tx.access_mode")
+	// TxOutcomeKey represents how a transaction ended, see the TxOutcome
+	// constants.
+	TxOutcomeKey = attribute.Key("pgx.tx.outcome")
+	// TxDurationKey represents the time in milliseconds between the start
+	// and the end of a transaction.
+	TxDurationKey = attribute.Key("pgx.tx.duration_ms")
+)
+
+// Values of the pgx.tx.outcome attribute.
+const (
+	TxOutcomeCommitted  = "committed"
+	TxOutcomeRolledBack = "rolled_back"
+	TxOutcomeFailed     = "failed"
+)
+
+// TxBeginner starts transactions. It is implemented by *pgx.Conn,
+// *pgxpool.Pool and *pgxpool.Conn.
+type TxBeginner interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
+// Begin is like BeginTx with the default transaction options.
+func (t *Tracer) Begin(ctx context.Context, db TxBeginner) (pgx.Tx, error) {
+	return t.BeginTx(ctx, db, pgx.TxOptions{})
+}
+
+// BeginTx starts a transaction on db and wraps it in a "transaction" span,
+// which ends when the transaction is committed or rolled back.
+//
+// Statements run through the returned pgx.Tx, including the BEGIN, COMMIT
+// and ROLLBACK statements, are traced as children of the transaction span
+// when they are given the context passed to BeginTx, or a context without
+// a span.
+func (t *Tracer) BeginTx(ctx context.Context, db TxBeginner, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		return db.BeginTx(ctx, txOptions)
+	}
+
+	isoLevel := string(txOptions.IsoLevel)
+	if isoLevel == "" {
+		isoLevel = "default"
+	}
+
+	accessMode := string(txOptions.AccessMode)
+	if accessMode == "" {
+		accessMode = "default"
+	}
+
+	opts := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(t.attrs...),
+		trace.WithAttributes(
+			TxIsoLevelKey.String(isoLevel),
+			TxAccessModeKey.String(accessMode),
+		),
+	}
+
+	parents := []trace.SpanContext{trace.SpanContextFromContext(ctx)}
+
+	tx, err := t.startTx(ctx, parents, "transaction", opts, func(ctx context.Context) (pgx.Tx, error) {
+		return db.BeginTx(ctx, txOptions)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// startTx starts a span named spanName and calls begin with a context
+// carrying it. Statements given a context carrying one of parents are
+// reparented to the span.
+func (t *Tracer) startTx(
+	ctx context.Context,
+	parents []trace.SpanContext,
+	spanName string,
+	opts []trace.SpanStartOption,
+	begin func(ctx context.Context) (pgx.Tx, error),
+) (*tracedTx, error) {
+	start := time.Now()
+
+	spanCtx, span := t.tracer.Start(ctx, spanName, opts...)
+
+	tx, err := begin(spanCtx)
+	if err != nil {
+		t.recordError(ctx, span, err)
+		span.SetAttributes(TxOutcomeKey.String(TxOutcomeFailed))
+		span.End()
+
+		return nil, err
+	}
+
+	span.SetAttributes(t.connAttributes(tx.Conn())...)
+
+	return &tracedTx{
+		Tx:      tx,
+		tracer:  t,
+		parents: parents,
+		span:    span,
+		start:   start,
+	}, nil
+}
+
+// tracedTx is a pgx.Tx that ends its span when committed or rolled back.
+type tracedTx struct {
+	pgx.Tx
+
+	tracer  *Tracer
+	parents []trace.SpanContext
+	span    trace.Span
+	start   time.Time
+	once    sync.Once
+}
+
+// spanContext returns ctx with the transaction span as the current span,
+// unless ctx already carries a span other than the ones the transaction was
+// started in.
+func (tx *tracedTx) spanContext(ctx context.Context) context.Context {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return trace.ContextWithSpan(ctx, tx.span)
+	}
+
+	for _, parent := range tx.parents {
+		if sc.Equal(parent) {
+			return trace.ContextWithSpan(ctx, tx.span)
+		}
+	}
+
+	return ctx
+}
+
+// end ends the transaction span once.
+func (tx *tracedTx) end(ctx context.Context, outcome string, err error) {
+	tx.once.Do(func() {
+		tx.tracer.recordError(ctx, tx.span, err)
+		tx.span.SetAttributes(
+			TxOutcomeKey.String(outcome),
+			TxDurationKey.Float64(milliseconds(time.Since(tx.start))),
+		)
+		tx.span.End()
+	})
+}
+
+// Begin starts a pseudo nested transaction traced as a "savepoint" span.
+func (tx *tracedTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	opts := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(tx.tracer.attrs...),
+	}
+
+	parents := append(slices.Clip(tx.parents), tx.span.SpanContext())
+
+	nested, err := tx.tracer.startTx(tx.spanContext(ctx), parents, "savepoint", opts, tx.Tx.Begin)
+	if err != nil {
+		return nil, err
+	}
+
+	return nested, nil
+}
+
+// Commit commits the transaction and ends its span.
+func (tx *tracedTx) Commit(ctx context.Context) error {
+	err := tx.Tx.Commit(tx.spanContext(ctx))
+	switch {
+	case errors.Is(err, pgx.ErrTxClosed):
+		// Already ended by an earlier Commit or Rollback.
+	case err != nil:
+		tx.end(ctx, TxOutcomeFailed, err)
+	default:
+		tx.end(ctx, TxOutcomeCommitted, nil)
+	}
+
+	return err
+}
+
+// Rollback rolls back the transaction and ends its span.
+func (tx *tracedTx) Rollback(ctx context.Context) error {
+	err := tx.Tx.Rollback(tx.spanContext(ctx))
+	switch {
+	case errors.Is(err, pgx.ErrTxClosed):
+		// Already ended by an earlier Commit or Rollback.
+	case err != nil:
+		tx.end(ctx, TxOutcomeFailed, err)
+	default:
+		tx.end(ctx, TxOutcomeRolledBack, nil)
+	}
+
+	return err
+}
+
+func (tx *tracedTx) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	return tx.Tx.CopyFrom(tx.spanContext(ctx), tableName, columnNames, rowSrc)
+}
+
+func (tx *tracedTx) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	return tx.Tx.SendBatch(tx.spanContext(ctx), b)
+}
+
+func (tx *tracedTx) Prepare(ctx context.Context, name, sql string) (*pgconn.StatementDescription, error) {
+	return tx.Tx.Prepare(tx.spanContext(ctx), name, sql)
+}
+
+func (tx *tracedTx) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	return tx.Tx.Exec(tx.spanContext(ctx), sql, arguments...)
+}
+
+func (tx *tracedTx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return tx.Tx.Query(tx.spanContext(ctx), sql, args...)
+}
+
+func (tx *tracedTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return tx.Tx.QueryRow(tx.spanContext(ctx), sql, args...)
+}
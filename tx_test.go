@@ -0,0 +1,160 @@
+package otelpgx
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// fakeTx is a pgx.Tx recording the span each statement was run in.
+type fakeTx struct {
+	pgx.Tx
+
+	commitErr error
+	execSpans []trace.SpanContext
+}
+
+func (tx *fakeTx) Conn() *pgx.Conn { return nil }
+
+func (tx *fakeTx) Begin(context.Context) (pgx.Tx, error) { return &fakeTx{}, nil }
+
+func (tx *fakeTx) Commit(context.Context) error { return tx.commitErr }
+
+func (tx *fakeTx) Rollback(context.Context) error { return nil }
+
+func (tx *fakeTx) Exec(ctx context.Context, _ string, _ ...any) (pgconn.CommandTag, error) {
+	tx.execSpans = append(tx.execSpans, trace.SpanContextFromContext(ctx))
+	return pgconn.CommandTag{}, nil
+}
+
+type fakeBeginner struct {
+	tx  *fakeTx
+	err error
+}
+
+func (b fakeBeginner) BeginTx(context.Context, pgx.TxOptions) (pgx.Tx, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.tx, nil
+}
+
+func TestTracer_BeginTx(t *testing.T) {
+	errCommit := errors.New("commit failed")
+
+	tests := []struct {
+		name        string
+		beginErr    error
+		commitErr   error
+		rollback    bool
+		options     pgx.TxOptions
+		wantOutcome string
+		wantIso     string
+		wantAccess  string
+	}{
+		{
+			name:        "Committed",
+			options:     pgx.TxOptions{IsoLevel: pgx.Serializable, AccessMode: pgx.ReadOnly},
+			wantOutcome: TxOutcomeCommitted,
+			wantIso:     "serializable",
+			wantAccess:  "read only",
+		},
+		{
+			name:        "Rolled back",
+			rollback:    true,
+			wantOutcome: TxOutcomeRolledBack,
+			wantIso:     "default",
+			wantAccess:  "default",
+		},
+		{
+			name:        "Commit failed",
+			commitErr:   errCommit,
+			wantOutcome: TxOutcomeFailed,
+			wantIso:     "default",
+			wantAccess:  "default",
+		},
+		{
+			name:        "Begin failed",
+			beginErr:    errors.New("begin failed"),
+			wantOutcome: TxOutcomeFailed,
+			wantIso:     "default",
+			wantAccess:  "default",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			tr := NewTracer(WithTracerProvider(tp))
+
+			ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+			defer parent.End()
+
+			fake := &fakeTx{commitErr: tt.commitErr}
+			tx, err := tr.BeginTx(ctx, fakeBeginner{tx: fake, err: tt.beginErr}, tt.options)
+			if tt.beginErr != nil {
+				if !errors.Is(err, tt.beginErr) || tx != nil {
+					t.Fatalf("BeginTx() = %v, %v, want nil, %v", tx, err, tt.beginErr)
+				}
+			} else {
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if _, err := tx.Exec(ctx, "SELECT 1"); err != nil {
+					t.Fatal(err)
+				}
+
+				if tt.rollback {
+					err = tx.Rollback(ctx)
+				} else {
+					err = tx.Commit(ctx)
+				}
+				if !errors.Is(err, tt.commitErr) {
+					t.Fatalf("unexpected error %v", err)
+				}
+			}
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("got %d ended spans, want 1", len(spans))
+			}
+
+			span := spans[0]
+			if span.Name() != "transaction" {
+				t.Errorf("span name = %v, want transaction", span.Name())
+			}
+			if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+				t.Errorf("transaction span is not a child of the caller's span")
+			}
+
+			attrs := attribute.NewSet(span.Attributes()...)
+			for key, want := range map[attribute.Key]string{
+				TxOutcomeKey:    tt.wantOutcome,
+				TxIsoLevelKey:   tt.wantIso,
+				TxAccessModeKey: tt.wantAccess,
+			} {
+				if got, _ := attrs.Value(key); got.AsString() != want {
+					t.Errorf("%v = %q, want %q", key, got.AsString(), want)
+				}
+			}
+
+			if tt.beginErr == nil {
+				if _, ok := attrs.Value(TxDurationKey); !ok {
+					t.Errorf("missing %v attribute", TxDurationKey)
+				}
+				if len(fake.execSpans) != 1 || fake.execSpans[0].SpanID() != span.SpanContext().SpanID() {
+					t.Errorf("statement was not run in the transaction span")
+				}
+			}
+		})
+	}
+}